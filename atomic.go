@@ -0,0 +1,124 @@
+package byteseq
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// An AtomicRandomByteSeq is a lock-free alternative to a sequence made by
+// NewSafeRandomSeq, for when many goroutines draw from one sequence at a
+// high rate. Values are claimed by setting their bit in the consumed bitmap
+// with a compare-and-swap, so no draw ever waits on another goroutine
+// holding a lock; a draw that loses a race simply tries again.
+//
+// It always draws from the package-global math/rand/v2 source, which is
+// itself safe for concurrent use, and only offers the basic operations.
+// Use a RandomByteSeq if you need its other features.
+type AtomicRandomByteSeq struct {
+	consumed [4]atomic.Uint64 // Bitmap of consumed values, 64 per word
+}
+
+// NewAtomicRandomSeq returns a new AtomicRandomByteSeq. As with NewRandomSeq,
+// consumedBytes holds optional values to exclude from the sequence.
+func NewAtomicRandomSeq(consumedBytes []byte) *AtomicRandomByteSeq {
+	seq := &AtomicRandomByteSeq{}
+	for _, b := range consumedBytes {
+		seq.Consume(b)
+	}
+	return seq
+}
+
+// HasMore reports whether the sequence has any more values to return. With
+// other goroutines drawing at the same time the answer may be out of date
+// by the time it is used, so the error from NextValue must still be checked.
+func (a *AtomicRandomByteSeq) HasMore() bool {
+	return a.Remaining() > 0
+}
+
+// Remaining returns how many values are left in the sequence. Like HasMore,
+// it is only a snapshot while other goroutines are drawing.
+func (a *AtomicRandomByteSeq) Remaining() int {
+	remaining := 0
+	for i := range a.consumed {
+		remaining += 64 - bits.OnesCount64(a.consumed[i].Load())
+	}
+	return remaining
+}
+
+// NextValue returns a random value that no other call has returned, or
+// ErrExhausted once every value has been handed out. It is safe to call
+// from any number of goroutines at once.
+func (a *AtomicRandomByteSeq) NextValue() (byte, error) {
+	for {
+		// Choose uniformly among the values that were free in a snapshot of
+		// the bitmap, then try to claim it. If the word changed under us,
+		// somebody else won the race and we start again.
+		var words [4]uint64
+		free := 0
+		for i := range words {
+			words[i] = a.consumed[i].Load()
+			free += 64 - bits.OnesCount64(words[i])
+		}
+		if free == 0 {
+			return 0, ErrExhausted
+		}
+
+		n := rand.IntN(free)
+		for i, word := range words {
+			wordFree := 64 - bits.OnesCount64(word)
+			if n >= wordFree {
+				n -= wordFree
+				continue
+			}
+
+			bit := nthZeroBit(word, n)
+			if a.consumed[i].CompareAndSwap(word, word|1<<bit) {
+				return byte(i<<6 | bit), nil
+			}
+			break
+		}
+	}
+}
+
+// Consume marks b as consumed, reporting whether it was newly consumed
+func (a *AtomicRandomByteSeq) Consume(b byte) bool {
+	word := &a.consumed[b>>6]
+	mask := uint64(1) << (b & 0x3F)
+	for {
+		old := word.Load()
+		if old&mask != 0 {
+			return false
+		}
+		if word.CompareAndSwap(old, old|mask) {
+			return true
+		}
+	}
+}
+
+// Release returns a consumed value to the sequence, reporting whether it
+// was actually consumed. Unlike RandomByteSeq.Release, there is no record
+// of the original exclusions, so they can be released too.
+func (a *AtomicRandomByteSeq) Release(b byte) bool {
+	word := &a.consumed[b>>6]
+	mask := uint64(1) << (b & 0x3F)
+	for {
+		old := word.Load()
+		if old&mask == 0 {
+			return false
+		}
+		if word.CompareAndSwap(old, old&^mask) {
+			return true
+		}
+	}
+}
+
+// nthZeroBit returns the position of the n'th (counting from zero) clear
+// bit in word, starting from the least significant end.
+func nthZeroBit(word uint64, n int) int {
+	free := ^word
+	for ; n > 0; n-- {
+		free &= free - 1 // drop the lowest set bit
+	}
+	return bits.TrailingZeros64(free)
+}
@@ -0,0 +1,60 @@
+package byteseq
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestAtomicSequenceConcurrentDraws(t *testing.T) {
+	byteSeq := NewAtomicRandomSeq([]byte{0x00, 0xFF})
+	results := make(chan byte, 256)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 64; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				value, err := byteSeq.NextValue()
+				if errors.Is(err, ErrExhausted) {
+					return
+				}
+				results <- value
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var returnedValueCounts [256]int
+	for value := range results {
+		returnedValueCounts[value]++
+	}
+	for value, count := range returnedValueCounts {
+		expected := 1
+		if value == 0x00 || value == 0xFF {
+			expected = 0
+		}
+		if count != expected {
+			t.Errorf("Expected %d occurrences of 0x%02x but got %d", expected, value, count)
+		}
+	}
+}
+
+func TestAtomicSequenceConsumeAndRelease(t *testing.T) {
+	byteSeq := NewAtomicRandomSeq(nil)
+
+	if !byteSeq.Consume(0x10) || byteSeq.Consume(0x10) {
+		t.Errorf("Expected only the first Consume of 0x10 to succeed")
+	}
+	if byteSeq.Remaining() != 255 {
+		t.Errorf("Expected 255 values remaining but got %d", byteSeq.Remaining())
+	}
+	if !byteSeq.Release(0x10) || byteSeq.Release(0x10) {
+		t.Errorf("Expected only the first Release of 0x10 to succeed")
+	}
+	if byteSeq.Remaining() != 256 {
+		t.Errorf("Expected 256 values remaining but got %d", byteSeq.Remaining())
+	}
+}
@@ -0,0 +1,89 @@
+package byteseq
+
+import "context"
+
+// NextN draws up to n values from the sequence in one call. If fewer than n
+// values remain, it returns all of them along with ErrExhausted, so a
+// partial batch is never lost. Asking for zero or fewer values returns an
+// empty slice and no error.
+func (r *RandomByteSeq) NextN(n int) ([]byte, error) {
+	_ = r.waitN(context.Background(), r.batchSize(n))
+
+	r.lock()
+	defer r.unlock()
+
+	if n <= 0 {
+		return []byte{}, nil
+	}
+	defer r.pauseReclaim()()
+
+	values := make([]byte, 0, min(n, r.remainingValues))
+	for len(values) < n {
+		value, err := r.output(r.draw())
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Fill draws values into dst until it is full or the sequence is exhausted,
+// returning how many values were written. As with NextN, a short fill
+// returns ErrExhausted along with the count. Fill doesn't allocate, which
+// makes it the better choice in hot paths.
+func (r *RandomByteSeq) Fill(dst []byte) (int, error) {
+	_ = r.waitN(context.Background(), r.batchSize(len(dst)))
+
+	r.lock()
+	defer r.unlock()
+	defer r.pauseReclaim()()
+
+	for i := range dst {
+		value, err := r.output(r.draw())
+		if err != nil {
+			return i, err
+		}
+		dst[i] = value
+	}
+	return len(dst), nil
+}
+
+// Drain draws every value that is left, in random order, leaving the
+// sequence exhausted. Draining an exhausted sequence returns an empty slice.
+func (r *RandomByteSeq) Drain() []byte {
+	_ = r.waitN(context.Background(), r.Remaining())
+
+	r.lock()
+	defer r.unlock()
+	defer r.pauseReclaim()()
+
+	// Take the count once, so the loop can't be kept going by values
+	// coming back to the sequence as it runs
+	values := make([]byte, 0, r.remainingValues)
+	for range r.remainingValues {
+		value, _ := r.output(r.draw())
+		values = append(values, value)
+	}
+	return values
+}
+
+// Skip draws and discards the next n values, returning how many were
+// skipped. It makes exactly the draws NextValue would, so skipping n values
+// of a seeded sequence leaves it where n calls to NextValue would, without
+// allocating anything. As with NextN, a short skip returns ErrExhausted
+// along with the count.
+func (r *RandomByteSeq) Skip(n int) (int, error) {
+	_ = r.waitN(context.Background(), r.batchSize(n))
+
+	r.lock()
+	defer r.unlock()
+	defer r.pauseReclaim()()
+
+	for i := 0; i < n; i++ {
+		if _, err := r.draw(); err != nil {
+			return i, err
+		}
+	}
+	return max(n, 0), nil
+}
@@ -0,0 +1,96 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNextNReturnsPartialBatch(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+
+	values, err := byteSeq.NextN(250)
+	if err != nil || len(values) != 250 {
+		t.Fatalf("Expected 250 values and no error but got %d (%v)", len(values), err)
+	}
+
+	values, err = byteSeq.NextN(32)
+	if !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted for a short batch but got %v", err)
+	}
+	if len(values) != 6 {
+		t.Errorf("Expected the last 6 values but got %d", len(values))
+	}
+}
+
+func TestFillWritesUniqueValues(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	buf := make([]byte, 200)
+	var returnedValueCounts [256]int
+
+	n, err := byteSeq.Fill(buf)
+	if n != 200 || err != nil {
+		t.Fatalf("Expected to fill 200 values but got %d (%v)", n, err)
+	}
+	for _, value := range buf[:n] {
+		returnedValueCounts[value]++
+	}
+
+	n, err = byteSeq.Fill(buf)
+	if n != 56 || !errors.Is(err, ErrExhausted) {
+		t.Fatalf("Expected a short fill of 56 with ErrExhausted but got %d (%v)", n, err)
+	}
+	for _, value := range buf[:n] {
+		returnedValueCounts[value]++
+	}
+
+	for value, count := range returnedValueCounts {
+		if count != 1 {
+			t.Errorf("Expected one occurrence of 0x%02x but got %d", value, count)
+		}
+	}
+}
+
+func TestDrainReturnsWhateverIsLeft(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x01, 0x02})
+	first, _ := byteSeq.NextValue()
+
+	values := byteSeq.Drain()
+	if len(values) != 253 {
+		t.Fatalf("Expected 253 drained values but got %d", len(values))
+	}
+	if byteSeq.HasMore() {
+		t.Errorf("Expected sequence to be exhausted after Drain")
+	}
+
+	for _, value := range values {
+		if value == first || value == 0x01 || value == 0x02 {
+			t.Errorf("Drain returned already consumed value 0x%02x", value)
+		}
+	}
+
+	if len(byteSeq.Drain()) != 0 {
+		t.Errorf("Expected nothing from draining an exhausted sequence")
+	}
+}
+
+func TestSkipMatchesDrawing(t *testing.T) {
+	skipped := NewRandomSeqSeeded(21, nil)
+	drawn := NewRandomSeqSeeded(21, nil)
+
+	n, err := skipped.Skip(100)
+	if n != 100 || err != nil {
+		t.Fatalf("Expected to skip 100 values, got %d and %v", n, err)
+	}
+	_, _ = drawn.NextN(100)
+
+	a, _ := skipped.NextValue()
+	b, _ := drawn.NextValue()
+	if a != b {
+		t.Errorf("Expected skipping to leave the sequence where drawing would, got 0x%02x and 0x%02x", a, b)
+	}
+
+	n, err = skipped.Skip(1000)
+	if n != 155 || !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected a short skip of 155 values with ErrExhausted, got %d and %v", n, err)
+	}
+}
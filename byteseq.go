@@ -6,25 +6,256 @@
 package byteseq
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/owenjklan/byteseq/internal/randutil"
+	"golang.org/x/time/rate"
 )
 
+// ErrExhausted is returned when a value is requested from a sequence that
+// has none left. Use errors.Is to check for it, as it may be wrapped with
+// further detail.
+var ErrExhausted = errors.New("sequence has been exhausted")
+
 // A RandomByteSeq returns byte values randomly and only once each.
 // Byte values can be marked as already having been consumed when
 // creating a new struct.
+//
+// A RandomByteSeq is not safe for concurrent use unless it was created with
+// WithLocking, or by NewSafeRandomSeq.
+//
+// The zero value is an empty sequence, with no values to draw or consume.
+// It is mainly useful as a target to decode saved state into.
 type RandomByteSeq struct {
-	consumedBytes   [32]byte // Bitmap for each of 256 possible values
+	consumedBytes   ByteSet // Bitmap for each of 256 possible values
 	remainingValues int
+
+	// Every value, arranged so the unconsumed ones are in
+	// pool[:remainingValues], with position[b] giving b's index in pool.
+	// Draws and consumes swap a value to the end of the unconsumed part,
+	// like an incremental Fisher-Yates shuffle.
+	pool     [256]byte
+	position [256]byte
+
+	src     Source        // nil means the package-global source
+	mu      *sync.Mutex   // nil unless created with WithLocking
+	weights *[256]float64 // nil for uniform draws
+	limiter *rate.Limiter // nil unless created with WithRateLimit
+
+	transform  func(byte) byte // nil unless created with WithTransform
+	ordering   Ordering        // nil for the usual random order
+	middleware []Middleware    // set with WithMiddleware
+	logger     *slog.Logger    // nil unless created with WithLogger
+	leases     *leaseState     // nil unless created with WithLeases
+
+	// What to do when a draw finds nothing left, with the number of times
+	// a cycling sequence has started over, and a channel closed to wake
+	// draws waiting for a value under ExhaustBlock
+	policy ExhaustionPolicy
+	epoch  int
+	wake   chan struct{}
+
+	// Values drawn so far, kept when created with WithHistory
+	keepHistory bool
+	history     []byte
+
+	// Candidate chosen by Peek, handed out by the next draw
+	peeked    byte
+	hasPeeked bool
+
+	// Values handed out by Reserve that are yet to be confirmed or aborted
+	reserved ByteSet
+
+	// Thresholds set with WithWatermark, functions set with OnConsume and
+	// OnExhausted, and how many times the OnExhausted functions are due to
+	// be called when the lock is released
+	watermarks       []watermark
+	onConsume        []func(byte, int)
+	onExhausted      []func()
+	exhaustedPending int
+
+	// State as it was straight after construction, used by Reset
+	excludedBytes     ByteSet
+	excludedRemaining int
+}
+
+// A Source is the minimal random number generator a RandomByteSeq needs.
+// It is the same as rand.Source from math/rand/v2, so the PCG and ChaCha8
+// generators can be used directly. *rand.Rand from either math/rand or
+// math/rand/v2 (or a generator of your own) can also be plugged in with
+// WithSource or NewRandomSeqFromSource.
+type Source interface {
+	Uint64() uint64
 }
 
 // NewRandomSeq returns a new RandomByteSeq. consumedBytes is a slice of
 // optional values to exclude from the sequence. Internally, they are considered
-// as having already been "consumed".
-func NewRandomSeq(consumedBytes []byte) *RandomByteSeq {
-	seq := &RandomByteSeq{}
+// as having already been "consumed". Further settings, such as the random
+// source, can be given as options:
+//
+//	seq := byteseq.NewRandomSeq(nil, byteseq.WithExcluded(0x00, 0xFF), byteseq.WithSeed(42))
+//
+// Values that are excluded more than once are simply excluded. NewRandomSeq
+// panics if an option is given a setting it can't use; NewRandomSeqChecked
+// reports both problems as errors instead.
+func NewRandomSeq(consumedBytes []byte, opts ...Option) *RandomByteSeq {
+	cfg := newConfig(opts)
+	if cfg.err != nil {
+		panic("byteseq: " + cfg.err.Error())
+	}
+	return newSeq(consumedBytes, cfg)
+}
+
+// NewRandomSeqChecked is NewRandomSeq for when the exclusions or options come
+// from untrusted input. Rather than quietly ignoring a value that is
+// excluded twice, it returns an error wrapping ErrDuplicateValue, and a bad
+// option setting is returned as an error wrapping ErrInvalidOption rather
+// than a panic.
+func NewRandomSeqChecked(consumedBytes []byte, opts ...Option) (*RandomByteSeq, error) {
+	cfg := newConfig(opts)
+	if cfg.err != nil {
+		return nil, cfg.err
+	}
+
+	var seen [256]bool
+	for _, values := range [][]byte{consumedBytes, cfg.excluded} {
+		for _, b := range values {
+			if seen[b] {
+				return nil, fmt.Errorf("%w: 0x%02x", ErrDuplicateValue, b)
+			}
+			seen[b] = true
+		}
+	}
+	return newSeq(consumedBytes, cfg), nil
+}
+
+// NewRandomSeqSeeded returns a new RandomByteSeq that draws from its own
+// random source, seeded with seed. Two sequences created with the same seed
+// and consumedBytes will return values in exactly the same order, which is
+// handy for tests and for replaying a problem seen in a previous run.
+func NewRandomSeqSeeded(seed int64, consumedBytes []byte) *RandomByteSeq {
+	return NewRandomSeq(consumedBytes, WithSeed(seed))
+}
+
+// NewRandomSeqSeededAt rebuilds the sequence NewRandomSeqSeeded(seed,
+// consumedBytes) would be after draws values had been drawn from it. A
+// consumer that records just its seed and how many values it has used can
+// pick up exactly where it left off after a crash, without persisting the
+// sequence itself. The replay only holds if the original sequence was
+// drawn from and nothing else: Consume, Release, Reset and the like change
+// what later draws return.
+func NewRandomSeqSeededAt(seed int64, draws int, consumedBytes []byte) *RandomByteSeq {
+	seq := NewRandomSeqSeeded(seed, consumedBytes)
+	_, _ = seq.Skip(draws)
+	return seq
+}
+
+// NewRandomSeqChaCha8 returns a new RandomByteSeq that draws from a ChaCha8
+// generator keyed with seed. ChaCha8 gives better quality randomness than
+// the PCG generator used by NewRandomSeqSeeded, and is still reproducible
+// for a given seed.
+func NewRandomSeqChaCha8(seed [32]byte, consumedBytes []byte) *RandomByteSeq {
+	return NewRandomSeq(consumedBytes, WithChaCha8(seed))
+}
+
+// NewRandomSeqNamed returns a new RandomByteSeq whose order is fixed by
+// label, so every component that creates a sequence for, say,
+// "job-42/phase-1" gets the same permutation without having to share a
+// seed or any other state. It is the same as NewRandomSeq with WithLabel.
+func NewRandomSeqNamed(label string, consumedBytes []byte) *RandomByteSeq {
+	return NewRandomSeq(consumedBytes, WithLabel(label))
+}
+
+// NewRandomSeqFromSource returns a new RandomByteSeq that uses src for all of
+// its draws instead of the package-global source. A Source is generally not
+// safe for concurrent use, so src should not be shared with other goroutines
+// while the sequence is in use. A nil src selects the package-global source.
+func NewRandomSeqFromSource(src Source, consumedBytes []byte) *RandomByteSeq {
+	return NewRandomSeq(consumedBytes, WithSource(src))
+}
+
+// NewRangeSeq returns a new RandomByteSeq over the inclusive range [lo, hi],
+// so NewRangeSeq(32, 126) returns each printable ASCII character once. The
+// values outside the range are treated as excluded. If lo is greater than
+// hi the sequence is empty. opts are the same as for NewRandomSeq.
+func NewRangeSeq(lo, hi byte, opts ...Option) *RandomByteSeq {
+	var consumedBytes []byte
+	for i := 0; i < 256; i++ {
+		if byte(i) < lo || byte(i) > hi {
+			consumedBytes = append(consumedBytes, byte(i))
+		}
+	}
+	return NewRandomSeq(consumedBytes, opts...)
+}
+
+// NewRandomSeqFromAllowed returns a new RandomByteSeq that only returns the
+// values in allowed, for when it's easier to list what is permitted than
+// what isn't. Every other value is treated as excluded, and duplicates in
+// allowed don't matter. opts are the same as for NewRandomSeq, so further
+// values can still be taken out with WithExcluded.
+func NewRandomSeqFromAllowed(allowed []byte, opts ...Option) *RandomByteSeq {
+	var isAllowed [256]bool
+	for _, b := range allowed {
+		isAllowed[b] = true
+	}
+
+	var consumedBytes []byte
+	for i := 0; i < 256; i++ {
+		if !isAllowed[i] {
+			consumedBytes = append(consumedBytes, byte(i))
+		}
+	}
+	return NewRandomSeq(consumedBytes, opts...)
+}
+
+// NewFromBitmap returns a new RandomByteSeq with the values set in consumed
+// treated as already consumed, for systems that exchange 256-bit occupancy
+// maps. Any [32]byte in the layout described for ByteSet can be passed, and
+// Bitmap gives the same form back. opts are the same as for NewRandomSeq.
+func NewFromBitmap(consumed ByteSet, opts ...Option) *RandomByteSeq {
+	return NewRandomSeq(slices.Collect(consumed.All()), opts...)
+}
+
+// NewSafeRandomSeq returns a new RandomByteSeq that is safe for concurrent
+// use by multiple goroutines. Every method takes an internal lock, so each
+// individual call is atomic: no value is ever handed out twice, no matter
+// how many goroutines are drawing from the sequence. Sequences of calls are
+// not atomic, though. Another goroutine may draw the last value between a
+// HasMore and a NextValue, so the error from NextValue must still be
+// checked, and a candidate reported by Peek may be taken by someone else
+// before it is accepted. It is the same as NewRandomSeq with WithLocking.
+func NewSafeRandomSeq(consumedBytes []byte) *RandomByteSeq {
+	return NewRandomSeq(consumedBytes, WithLocking())
+}
+
+func newSeq(consumedBytes []byte, cfg *config) *RandomByteSeq {
+	seq := &RandomByteSeq{
+		src:         cfg.src,
+		weights:     cfg.weights,
+		limiter:     cfg.limiter,
+		keepHistory: cfg.history,
+		policy:      cfg.policy,
+		transform:   cfg.transform,
+		ordering:    cfg.ordering,
+		middleware:  cfg.middleware,
+		logger:      cfg.logger,
+		leases:      newLeaseState(cfg.leaseTTL, cfg.clock),
+	}
+	if cfg.locking {
+		seq.mu = &sync.Mutex{}
+	}
 
 	seq.remainingValues = 256
+	for i := range seq.pool {
+		seq.pool[i] = byte(i)
+		seq.position[i] = byte(i)
+	}
 
 	// Were any already-consumed bytes specified?
 	if len(consumedBytes) > 0 {
@@ -32,53 +263,435 @@ func NewRandomSeq(consumedBytes []byte) *RandomByteSeq {
 			seq.consumeByte(b)
 		}
 	}
+	for _, b := range cfg.excluded {
+		seq.consumeByte(b)
+	}
+	for _, keep := range cfg.filters {
+		for i := 0; i < 256; i++ {
+			if !keep(byte(i)) {
+				seq.consumeByte(byte(i))
+			}
+		}
+	}
+	seq.excludedBytes = seq.consumedBytes
+	seq.excludedRemaining = seq.remainingValues
+	seq.watermarks = slices.Clone(cfg.watermarks)
+	seq.onConsume = cfg.onConsume
+	seq.onExhausted = cfg.onExhausted
 	return seq
 }
 
 // determine if a given byte value is marked as consumed in the internal bitmap structures
 func (r *RandomByteSeq) valueHasBeenConsumed(b byte) bool {
-	// determine if a given byte value is marked as consumed in the internal bitmap structures
-	// Get the correct byte of our bitmap from top 5 bits
-	byteIndex := b & 0xF8 >> 3
-	testByte := r.consumedBytes[byteIndex]
-
-	// Now, from the bottom 3 bits, check the appropriate bit
-	bitMask := byte(1 << (b & 0x07))
-
-	return testByte&bitMask != 0
+	return r.consumedBytes.Contains(b)
 }
 
 func (r *RandomByteSeq) consumeByte(b byte) {
 	// marks a given byte value as having been consumed in the internal bitmap structures.
-	byteIndex := b & 0xF8 >> 3
-	bitMask := byte(1 << (b & 0x07))
-
-	r.consumedBytes[byteIndex] |= bitMask
+	// Consuming a value twice would corrupt the pool, so that's a no-op, as
+	// is consuming anything from the zero value, which has no pool.
+	if r.valueHasBeenConsumed(b) || r.remainingValues == 0 {
+		return
+	}
+	r.consumedBytes.Add(b)
 	r.remainingValues--
+	r.swapInPool(b, r.pool[r.remainingValues])
+	r.checkWatermarks()
+}
+
+func (r *RandomByteSeq) releaseByte(b byte) {
+	r.returnByte(b)
+	r.debugValue("value released", b)
+}
+
+// returnByte is releaseByte without the logging, for callers that log the
+// value coming back as something else
+func (r *RandomByteSeq) returnByte(b byte) {
+	// clears the consumed mark for a given byte value in the internal bitmap structures.
+	r.consumedBytes.Remove(b)
+	r.reserved.Remove(b)
+	r.endLease(b)
+	r.swapInPool(b, r.pool[r.remainingValues])
+	r.remainingValues++
+	r.checkWatermarks()
+}
+
+// swapInPool exchanges the pool positions of values a and b
+func (r *RandomByteSeq) swapInPool(a, b byte) {
+	i, j := r.position[a], r.position[b]
+	r.pool[i], r.pool[j] = b, a
+	r.position[a], r.position[b] = j, i
+}
+
+// rebuildPool lays the pool out afresh from the consumed bitmap, in numeric
+// order, for when the bitmap has been replaced wholesale.
+func (r *RandomByteSeq) rebuildPool() {
+	unconsumed, consumed := 0, r.remainingValues
+	for i := 0; i < 256; i++ {
+		b := byte(i)
+		if r.valueHasBeenConsumed(b) {
+			r.pool[consumed] = b
+			r.position[b] = byte(consumed)
+			consumed++
+		} else {
+			r.pool[unconsumed] = b
+			r.position[b] = byte(unconsumed)
+			unconsumed++
+		}
+	}
+}
+
+// lock and unlock guard the sequence's state when it was created with
+// WithLocking, and do nothing otherwise. They are only used by the
+// exported methods; internal helpers assume the lock is already held. As
+// every change to the state is made under the lock, unlock is also where
+// draws waiting for a value are woken, once there is one.
+func (r *RandomByteSeq) lock() {
+	if r.mu != nil {
+		r.mu.Lock()
+	}
+}
+
+func (r *RandomByteSeq) unlock() {
+	if r.wake != nil && r.remainingValues > 0 {
+		close(r.wake)
+		r.wake = nil
+	}
+
+	exhausted, times := r.onExhausted, r.exhaustedPending
+	r.exhaustedPending = 0
+	if r.mu != nil {
+		r.mu.Unlock()
+	}
+	notifyExhausted(exhausted, times)
 }
 
 // HasMore is intended to check if the RandomByteSeq has any more values
 // that it can return.
 func (r *RandomByteSeq) HasMore() bool {
+	r.lock()
+	defer r.unlock()
+	r.reclaim()
 	return r.remainingValues > 0
 }
 
+// Remaining returns how many values can still be drawn from the sequence
+// before it is exhausted.
+func (r *RandomByteSeq) Remaining() int {
+	r.lock()
+	defer r.unlock()
+	r.reclaim()
+	return r.remainingValues
+}
+
+// Consumed returns every value that has been consumed so far, in numeric
+// order. This includes values that were excluded when the sequence was
+// created as well as those that have been handed out by NextValue.
+func (r *RandomByteSeq) Consumed() []byte {
+	r.lock()
+	defer r.unlock()
+
+	consumed := make([]byte, 0, 256-r.remainingValues)
+	for i := 0; i < 256; i++ {
+		if r.valueHasBeenConsumed(byte(i)) {
+			consumed = append(consumed, byte(i))
+		}
+	}
+	return consumed
+}
+
+// Unconsumed returns every value that has not been consumed yet, in numeric
+// order. The values are not marked as consumed; they remain available to
+// NextValue.
+func (r *RandomByteSeq) Unconsumed() []byte {
+	r.lock()
+	defer r.unlock()
+
+	unconsumed := make([]byte, 0, r.remainingValues)
+	for i := 0; i < 256; i++ {
+		if !r.valueHasBeenConsumed(byte(i)) {
+			unconsumed = append(unconsumed, byte(i))
+		}
+	}
+	return unconsumed
+}
+
+// Bitmap returns the set of consumed values as a 256-bit bitmap, in the
+// layout described for ByteSet. It can be used as a plain [32]byte, and
+// NewFromBitmap turns it back into a sequence.
+func (r *RandomByteSeq) Bitmap() ByteSet {
+	r.lock()
+	defer r.unlock()
+	return r.consumedBytes
+}
+
+// IsConsumed reports whether b has already been returned by the sequence,
+// or was excluded from it, so callers don't have to keep track themselves.
+// A candidate reported by Peek is not consumed until it is accepted.
+func (r *RandomByteSeq) IsConsumed(b byte) bool {
+	r.lock()
+	defer r.unlock()
+	return r.valueHasBeenConsumed(b)
+}
+
+// Consume marks b as consumed, so it will never be returned by the
+// sequence. This is for values that have been claimed by some other means
+// after the sequence was created. The returned bool reports whether b was
+// newly consumed; consuming a value twice is harmless and reports false.
+// The error is currently always nil, but callers should check it so that
+// sequences with stricter rules can refuse a value in future.
+func (r *RandomByteSeq) Consume(b byte) (bool, error) {
+	r.lock()
+	defer r.unlock()
+
+	if r.valueHasBeenConsumed(b) || r.remainingValues == 0 {
+		return false, nil
+	}
+
+	// Don't hand out a value that's been taken from under a Peek
+	if r.hasPeeked && r.peeked == b {
+		r.hasPeeked = false
+	}
+	r.consumeByte(b)
+	return true, nil
+}
+
+// Release returns a previously consumed value to the sequence, so it can be
+// handed out again by a later draw. Values that were excluded when the
+// sequence was created stay excluded. The returned bool reports whether b
+// was actually released, which is false if it wasn't consumed or is one
+// of the original exclusions.
+func (r *RandomByteSeq) Release(b byte) bool {
+	r.lock()
+	defer r.unlock()
+
+	if !r.valueHasBeenConsumed(b) || r.isExcluded(b) {
+		return false
+	}
+
+	r.releaseByte(b)
+	return true
+}
+
+// isExcluded reports whether b was one of the values excluded at construction
+func (r *RandomByteSeq) isExcluded(b byte) bool {
+	return r.excludedBytes.Contains(b)
+}
+
+// Reset returns the sequence to the state it was in when it was created, so
+// every value is available again except those that were excluded at
+// construction. The random source is left as it is, so a seeded sequence
+// will not repeat its previous order after a Reset.
+func (r *RandomByteSeq) Reset() {
+	r.lock()
+	defer r.unlock()
+
+	r.consumedBytes = r.excludedBytes
+	r.remainingValues = r.excludedRemaining
+	r.hasPeeked = false
+	r.reserved = ByteSet{}
+	r.history = nil
+	r.epoch = 0
+	r.resetLeases()
+	r.rebuildPool()
+	r.checkWatermarks()
+	r.debug("sequence reset")
+}
+
+// Clone returns an independent copy of the sequence with the same consumed
+// state. Values drawn from the copy do not affect the original and vice
+// versa. The copy gets its own copy of the random source when it is one of
+// the math/rand/v2 generators, so a seeded clone continues with exactly the
+// draws the original would have made. Any other Source is shared between
+// the two sequences.
+func (r *RandomByteSeq) Clone() *RandomByteSeq {
+	r.lock()
+	defer r.unlock()
+
+	dup := *r
+	dup.src = cloneSource(r.src)
+	dup.ordering = cloneOrdering(r.ordering)
+	dup.history = slices.Clone(r.history)
+	dup.wake = nil
+	dup.leases = r.leases.clone()
+	dup.cloneHooks()
+	if r.mu != nil {
+		dup.mu = &sync.Mutex{}
+	}
+	return &dup
+}
+
 // NextValue returns either the next random byte value that hasn't been
-// previously returned, or will return an error if an attempt is made
+// previously returned, or will return ErrExhausted if an attempt is made
 // to get a value from an exhausted sequence. The HasMore function
 // can be used to avoid this.
 func (r *RandomByteSeq) NextValue() (byte, error) {
+	if r.middleware != nil {
+		return r.throughMiddleware(context.Background())
+	}
+	_ = r.wait(context.Background())
+
+	r.lock()
+	defer r.unlock()
+	return r.output(r.draw())
+}
+
+// MustNextValue is like NextValue but panics if the sequence is exhausted.
+// It is meant for initialisation code and tests, where the caller already
+// knows there are values left and handling an error would be pure noise.
+func (r *RandomByteSeq) MustNextValue() byte {
+	value, err := r.NextValue()
+	if err != nil {
+		panic("byteseq: " + err.Error())
+	}
+	return value
+}
+
+// TryNextValue is a comma-ok form of NextValue, reporting false instead of
+// returning an error once the sequence is exhausted. In hot loops it saves
+// creating and checking an error on every draw.
+func (r *RandomByteSeq) TryNextValue() (byte, bool) {
+	_ = r.wait(context.Background())
+
+	r.lock()
+	defer r.unlock()
+
+	if !r.available() {
+		return 0, false
+	}
+	value, _ := r.output(r.draw())
+	return value, true
+}
+
+// NextValueCtx is NextValue for draws that may have to wait, such as under
+// WithRateLimit or ExhaustBlock, returning an error if ctx is done before a
+// value can be drawn. A draw that doesn't need to wait still fails if ctx is already
+// done, so a cancelled consumer doesn't carry on taking values.
+func (r *RandomByteSeq) NextValueCtx(ctx context.Context) (byte, error) {
+	if r.middleware != nil {
+		return r.throughMiddleware(ctx)
+	}
+	return r.output(r.nextRaw(ctx))
+}
+
+// nextRaw is NextValueCtx without the transform, for methods that may have
+// to release the value again.
+func (r *RandomByteSeq) nextRaw(ctx context.Context) (byte, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := r.wait(ctx); err != nil {
+		return 0, err
+	}
+
+	r.lock()
+	defer r.unlock()
+	return r.drawCtx(ctx)
+}
+
+// draw is NextValue without the locking, for use by the other draw methods
+func (r *RandomByteSeq) draw() (byte, error) {
+	return r.drawCtx(context.Background())
+}
+
+// drawCtx is draw for NextValueCtx, giving up waiting under ExhaustBlock
+// once ctx is done.
+func (r *RandomByteSeq) drawCtx(ctx context.Context) (byte, error) {
 	// Are there any more values available?
-	if r.remainingValues == 0 {
-		return 0, fmt.Errorf("sequence has been exhausted")
+	if zero, err := r.awaitAvailable(ctx); zero || err != nil {
+		return 0, err
 	}
 
-	for {
-		valueByte := byte(rand.Int() & 0xFF)
+	valueByte := r.take()
+	r.record(valueByte)
+	return valueByte, nil
+}
 
-		if !r.valueHasBeenConsumed(valueByte) {
-			r.consumeByte(valueByte)
-			return valueByte, nil
-		}
+// take chooses and consumes a value without recording it as drawn, for
+// moving values between sequences. The sequence must not be exhausted.
+func (r *RandomByteSeq) take() byte {
+	valueByte := r.pick()
+	r.consumeByte(valueByte)
+	return valueByte
+}
+
+// Peek returns the value the next call to NextValue will return, without
+// consuming it. Repeated calls to Peek return the same candidate until it is
+// either accepted with NextValue (or Accept), or passed over with Reject.
+// Peek returns ErrExhausted if the sequence has no more values.
+func (r *RandomByteSeq) Peek() (byte, error) {
+	r.lock()
+	defer r.unlock()
+
+	if !r.available() {
+		return 0, ErrExhausted
+	}
+
+	if !r.hasPeeked {
+		r.peeked = r.randomUnconsumed()
+		r.hasPeeked = true
+	}
+	return r.output(r.peeked, nil)
+}
+
+// Accept consumes and returns the candidate reported by Peek. It is the
+// same as calling NextValue, but reads better in propose-and-confirm code.
+func (r *RandomByteSeq) Accept() (byte, error) {
+	return r.NextValue()
+}
+
+// Reject passes over the candidate reported by Peek, leaving it unconsumed.
+// The next Peek or NextValue makes a fresh random choice, which may turn out
+// to be the rejected value again as it is still part of the sequence.
+func (r *RandomByteSeq) Reject() {
+	r.lock()
+	defer r.unlock()
+	r.hasPeeked = false
+}
+
+// pick chooses the next value to hand out, preferring a pending Peek
+// candidate. The sequence must not be exhausted.
+func (r *RandomByteSeq) pick() byte {
+	if r.hasPeeked {
+		r.hasPeeked = false
+		return r.peeked
+	}
+	return r.randomUnconsumed()
+}
+
+// randomUnconsumed returns a random value that hasn't been consumed yet.
+// As the unconsumed values are kept together at the front of the pool, this
+// takes a single random number however few values are left. The sequence
+// must not be exhausted.
+func (r *RandomByteSeq) randomUnconsumed() byte {
+	if r.ordering != nil {
+		return r.choose(r.unconsumed())
+	}
+	if r.weights != nil {
+		return r.weightedUnconsumed()
+	}
+	return r.pool[r.intN(r.remainingValues)]
+}
+
+// cloneSource copies the state of the generators we know how to copy, so a
+// cloned sequence doesn't disturb the draws of the original.
+func cloneSource(src Source) Source {
+	switch s := src.(type) {
+	case *rand.PCG:
+		dup := *s
+		return &dup
+	case *rand.ChaCha8:
+		dup := *s
+		return &dup
+	}
+	return src
+}
+
+// intN returns a uniformly distributed number in [0, n) from the
+// sequence's own source, falling back to the package-global one.
+func (r *RandomByteSeq) intN(n int) int {
+	if r.src == nil {
+		return rand.IntN(n)
 	}
+	return int(randutil.Uint64n(r.src, uint64(n)))
 }
@@ -1,6 +1,11 @@
 package byteseq
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
 	"testing"
 )
 
@@ -24,6 +29,26 @@ func TestSettingBytesAsConsumed(t *testing.T) {
 	}
 }
 
+func TestRemainingAfterSettingConsumed(t *testing.T) {
+	// Remaining should count down from 252 to nothing as the values
+	// left after setting 4 bytes are drawn
+	testBytes := []byte{0x00, 0x01, 0x02, 0x03}
+	expectedBytesCount := 256 - len(testBytes)
+
+	byteSeq := NewRandomSeq(testBytes)
+	if byteSeq.Remaining() != expectedBytesCount {
+		t.Errorf("Expected %d bytes remaining but got %d", expectedBytesCount, byteSeq.Remaining())
+	}
+
+	for byteSeq.HasMore() {
+		_, _ = byteSeq.NextValue()
+	}
+
+	if byteSeq.Remaining() != 0 {
+		t.Errorf("Expected no bytes remaining but got %d", byteSeq.Remaining())
+	}
+}
+
 // TODO: This can be improved using more values in a table-driven structure
 func TestBasicMarkingOfConsumedValue(t *testing.T) {
 	byteSeq := NewRandomSeq(nil)
@@ -47,6 +72,9 @@ func TestExhaustedSequenceReturnsError(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected error from exhausted byte sequence, but got nil")
 	}
+	if !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
 }
 
 func TestNoByteValueIsRepeated(t *testing.T) {
@@ -65,3 +93,537 @@ func TestNoByteValueIsRepeated(t *testing.T) {
 		}
 	}
 }
+
+func TestSeededSequencesAreReproducible(t *testing.T) {
+	first := NewRandomSeqSeeded(42, []byte{0x10, 0x20})
+	second := NewRandomSeqSeeded(42, []byte{0x10, 0x20})
+
+	for first.HasMore() {
+		a, _ := first.NextValue()
+		b, err := second.NextValue()
+		if err != nil {
+			t.Fatalf("Second sequence exhausted early: %v", err)
+		}
+		if a != b {
+			t.Fatalf("Expected identical draw order but got 0x%02x and 0x%02x", a, b)
+		}
+	}
+}
+
+// countingSource wraps a real generator and records how often it is used
+type countingSource struct {
+	rng   *rand.Rand
+	calls int
+}
+
+func (c *countingSource) Uint64() uint64 {
+	c.calls++
+	return c.rng.Uint64()
+}
+
+func TestSuppliedSourceIsUsedForDraws(t *testing.T) {
+	src := &countingSource{rng: rand.New(rand.NewPCG(7, 0))}
+	byteSeq := NewRandomSeqFromSource(src, nil)
+
+	for byteSeq.HasMore() {
+		_, _ = byteSeq.NextValue()
+	}
+
+	// Every draw needs at least one value from the source
+	if src.calls < 256 {
+		t.Errorf("Expected at least 256 calls to the source but got %d", src.calls)
+	}
+}
+
+func TestChaCha8SequenceCoversAllValues(t *testing.T) {
+	var seed [32]byte
+	byteSeq := NewRandomSeqChaCha8(seed, nil)
+	var returnedValueCounts [256]int
+
+	for byteSeq.HasMore() {
+		value, _ := byteSeq.NextValue()
+		returnedValueCounts[value]++
+	}
+
+	for value, count := range returnedValueCounts {
+		if count != 1 {
+			t.Errorf("Expected one occurrence of 0x%02x but got %d", value, count)
+		}
+	}
+}
+
+func TestResetRestoresExclusions(t *testing.T) {
+	testBytes := []byte{0x00, 0x01, 0x02, 0x03}
+	byteSeq := NewRandomSeq(testBytes)
+
+	for round := 0; round < 2; round++ {
+		var returnedValueCounts [256]int
+		for byteSeq.HasMore() {
+			value, _ := byteSeq.NextValue()
+			returnedValueCounts[value]++
+		}
+
+		for value, count := range returnedValueCounts {
+			expected := 1
+			if value < len(testBytes) {
+				expected = 0
+			}
+			if count != expected {
+				t.Errorf("Round %d: expected %d occurrences of 0x%02x but got %d", round, expected, value, count)
+			}
+		}
+		byteSeq.Reset()
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	original := NewRandomSeqSeeded(99, nil)
+	for i := 0; i < 100; i++ {
+		_, _ = original.NextValue()
+	}
+
+	clone := original.Clone()
+	for clone.HasMore() {
+		_, _ = clone.NextValue()
+	}
+
+	if !original.HasMore() {
+		t.Fatalf("Draining the clone exhausted the original")
+	}
+
+	// A seeded clone should continue with the same draws as the original
+	replay := original.Clone()
+	for original.HasMore() {
+		a, _ := original.NextValue()
+		b, _ := replay.NextValue()
+		if a != b {
+			t.Fatalf("Expected clone to draw 0x%02x but got 0x%02x", a, b)
+		}
+	}
+}
+
+func TestConsumedListsExcludedAndDrawnValues(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x80, 0x05})
+	drawn, _ := byteSeq.NextValue()
+
+	consumed := byteSeq.Consumed()
+	if len(consumed) != 3 {
+		t.Fatalf("Expected 3 consumed values but got %d: %v", len(consumed), consumed)
+	}
+
+	for i := 1; i < len(consumed); i++ {
+		if consumed[i-1] >= consumed[i] {
+			t.Errorf("Expected consumed values in numeric order but got %v", consumed)
+		}
+	}
+
+	for _, want := range []byte{0x05, 0x80, drawn} {
+		found := false
+		for _, b := range consumed {
+			found = found || b == want
+		}
+		if !found {
+			t.Errorf("Expected 0x%02x in consumed values %v", want, consumed)
+		}
+	}
+}
+
+func TestUnconsumedComplementsConsumed(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00, 0x7F, 0xFF})
+	for i := 0; i < 10; i++ {
+		_, _ = byteSeq.NextValue()
+	}
+
+	unconsumed := byteSeq.Unconsumed()
+	if len(unconsumed) != byteSeq.Remaining() {
+		t.Fatalf("Expected %d unconsumed values but got %d", byteSeq.Remaining(), len(unconsumed))
+	}
+
+	var seen [256]bool
+	for _, b := range byteSeq.Consumed() {
+		seen[b] = true
+	}
+	for i, b := range unconsumed {
+		if seen[b] {
+			t.Errorf("Value 0x%02x reported as both consumed and unconsumed", b)
+		}
+		if i > 0 && unconsumed[i-1] >= b {
+			t.Errorf("Expected unconsumed values in numeric order but got %v", unconsumed)
+		}
+	}
+}
+
+func TestIsConsumed(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x10})
+	if !byteSeq.IsConsumed(0x10) {
+		t.Errorf("Expected excluded value 0x10 to be reported as consumed")
+	}
+
+	peeked, _ := byteSeq.Peek()
+	if byteSeq.IsConsumed(peeked) {
+		t.Errorf("Expected peeked value 0x%02x not to be consumed yet", peeked)
+	}
+
+	value, _ := byteSeq.NextValue()
+	if !byteSeq.IsConsumed(value) {
+		t.Errorf("Expected drawn value 0x%02x to be reported as consumed", value)
+	}
+	for _, b := range byteSeq.Unconsumed() {
+		if byteSeq.IsConsumed(b) {
+			t.Errorf("Unconsumed value 0x%02x reported as consumed", b)
+		}
+	}
+}
+
+func TestBitmapRoundTrip(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00, 0x09})
+	_, _ = byteSeq.NextN(20)
+
+	var raw [32]byte = byteSeq.Bitmap()
+	if raw[0]&0x01 == 0 || raw[1]&0x02 == 0 {
+		t.Errorf("Expected bits for 0x00 and 0x09 to be set in the bitmap")
+	}
+
+	restored := NewFromBitmap(raw)
+	if restored.Remaining() != byteSeq.Remaining() {
+		t.Errorf("Expected %d values in the restored sequence, got %d", byteSeq.Remaining(), restored.Remaining())
+	}
+	if restored.Bitmap() != byteSeq.Bitmap() {
+		t.Errorf("Expected the restored sequence to have the same bitmap")
+	}
+	checkPool(t, restored)
+}
+
+func TestPeekDoesNotConsume(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+
+	first, _ := byteSeq.Peek()
+	second, _ := byteSeq.Peek()
+	if first != second {
+		t.Errorf("Expected repeated Peek to return 0x%02x but got 0x%02x", first, second)
+	}
+	if byteSeq.Remaining() != 256 {
+		t.Errorf("Expected Peek not to consume but %d values remain", byteSeq.Remaining())
+	}
+
+	accepted, _ := byteSeq.Accept()
+	if accepted != first {
+		t.Errorf("Expected Accept to return peeked 0x%02x but got 0x%02x", first, accepted)
+	}
+	if byteSeq.Remaining() != 255 {
+		t.Errorf("Expected Accept to consume but %d values remain", byteSeq.Remaining())
+	}
+}
+
+func TestRejectedValueRemainsAvailable(t *testing.T) {
+	// Leave exactly two values so the rejected one must come back
+	var consumedBytes []byte
+	for i := 0; i < 254; i++ {
+		consumedBytes = append(consumedBytes, byte(i))
+	}
+	byteSeq := NewRandomSeq(consumedBytes)
+
+	rejected, _ := byteSeq.Peek()
+	byteSeq.Reject()
+
+	var returned [256]bool
+	for byteSeq.HasMore() {
+		value, _ := byteSeq.NextValue()
+		returned[value] = true
+	}
+	if !returned[rejected] {
+		t.Errorf("Expected rejected value 0x%02x to be returned later", rejected)
+	}
+}
+
+func TestConsumeAfterConstruction(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+
+	peeked, _ := byteSeq.Peek()
+	newlyConsumed, err := byteSeq.Consume(peeked)
+	if !newlyConsumed || err != nil {
+		t.Errorf("Expected (true, nil) from first Consume but got (%v, %v)", newlyConsumed, err)
+	}
+
+	newlyConsumed, _ = byteSeq.Consume(peeked)
+	if newlyConsumed {
+		t.Errorf("Expected second Consume of 0x%02x to report false", peeked)
+	}
+	if byteSeq.Remaining() != 255 {
+		t.Errorf("Expected 255 values remaining but got %d", byteSeq.Remaining())
+	}
+
+	for byteSeq.HasMore() {
+		value, _ := byteSeq.NextValue()
+		if value == peeked {
+			t.Errorf("Consumed value 0x%02x was still returned", value)
+		}
+	}
+}
+
+func TestReleaseReturnsValueToPool(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00})
+	for byteSeq.HasMore() {
+		_, _ = byteSeq.NextValue()
+	}
+
+	if !byteSeq.Release(0x42) {
+		t.Fatalf("Expected Release of drawn value 0x42 to succeed")
+	}
+	if byteSeq.Release(0x42) {
+		t.Errorf("Expected second Release of 0x42 to report false")
+	}
+	if byteSeq.Release(0x00) {
+		t.Errorf("Expected Release of excluded value 0x00 to report false")
+	}
+
+	value, err := byteSeq.NextValue()
+	if err != nil || value != 0x42 {
+		t.Errorf("Expected released value 0x42 but got 0x%02x (%v)", value, err)
+	}
+	if byteSeq.HasMore() {
+		t.Errorf("Expected sequence to be exhausted again")
+	}
+}
+
+func TestSafeSequenceConcurrentDraws(t *testing.T) {
+	byteSeq := NewSafeRandomSeq(nil)
+	results := make(chan byte, 256)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				value, err := byteSeq.NextValue()
+				if err != nil {
+					return
+				}
+				results <- value
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var returnedValueCounts [256]int
+	for value := range results {
+		returnedValueCounts[value]++
+	}
+	for value, count := range returnedValueCounts {
+		if count != 1 {
+			t.Errorf("Expected one occurrence of 0x%02x but got %d", value, count)
+		}
+	}
+}
+
+// checkPool verifies the pool holds exactly the unconsumed values up front
+func checkPool(t *testing.T, byteSeq *RandomByteSeq) {
+	t.Helper()
+	for i, b := range byteSeq.pool {
+		if int(byteSeq.position[b]) != i {
+			t.Fatalf("Value 0x%02x is at pool index %d but position says %d", b, i, byteSeq.position[b])
+		}
+		if unconsumed := i < byteSeq.remainingValues; unconsumed == byteSeq.valueHasBeenConsumed(b) {
+			t.Fatalf("Value 0x%02x at pool index %d disagrees with the bitmap", b, i)
+		}
+	}
+}
+
+func TestPoolStaysConsistent(t *testing.T) {
+	// Duplicate exclusions must only be counted once
+	byteSeq := NewRandomSeq([]byte{0x10, 0x10, 0x20})
+	if byteSeq.Remaining() != 254 {
+		t.Errorf("Expected 254 values remaining but got %d", byteSeq.Remaining())
+	}
+	checkPool(t, byteSeq)
+
+	for i := 0; i < 100; i++ {
+		_, _ = byteSeq.NextValue()
+	}
+	checkPool(t, byteSeq)
+
+	byteSeq.Release(byteSeq.Consumed()[5])
+	_, _ = byteSeq.Consume(byteSeq.Unconsumed()[7])
+	checkPool(t, byteSeq)
+
+	byteSeq.Reset()
+	checkPool(t, byteSeq)
+}
+
+func BenchmarkDrainSequence(b *testing.B) {
+	byteSeq := NewRandomSeqSeeded(1, nil)
+	for i := 0; i < b.N; i++ {
+		for byteSeq.HasMore() {
+			_, _ = byteSeq.NextValue()
+		}
+		byteSeq.Reset()
+	}
+}
+
+// The last value should cost the same as the first; with rejection sampling
+// it used to take around 256 attempts on average.
+func BenchmarkNextValueNearlyExhausted(b *testing.B) {
+	byteSeq := NewRandomSeqSeeded(1, nil)
+	for i := 0; i < 255; i++ {
+		_, _ = byteSeq.NextValue()
+	}
+	last := byteSeq.Unconsumed()[0]
+
+	for i := 0; i < b.N; i++ {
+		_, _ = byteSeq.NextValue()
+		byteSeq.Release(last)
+	}
+}
+
+func TestRangeSeqStaysInRange(t *testing.T) {
+	byteSeq := NewRangeSeq(32, 126)
+	if byteSeq.Remaining() != 95 {
+		t.Errorf("Expected 95 printable characters but got %d", byteSeq.Remaining())
+	}
+
+	for byteSeq.HasMore() {
+		value, _ := byteSeq.NextValue()
+		if value < 32 || value > 126 {
+			t.Errorf("Value 0x%02x is outside the range", value)
+		}
+	}
+
+	if NewRangeSeq(10, 5).HasMore() {
+		t.Errorf("Expected an inverted range to be empty")
+	}
+	if NewRangeSeq(0, 255).Remaining() != 256 {
+		t.Errorf("Expected the full range to hold every value")
+	}
+}
+
+func TestSeededAtResumesPosition(t *testing.T) {
+	exclusions := []byte{0x00, 0xFF}
+	original := NewRandomSeqSeeded(2024, exclusions)
+	for i := 0; i < 75; i++ {
+		_, _ = original.NextValue()
+	}
+
+	resumed := NewRandomSeqSeededAt(2024, 75, exclusions)
+	if resumed.Remaining() != original.Remaining() {
+		t.Fatalf("Expected %d values remaining but got %d", original.Remaining(), resumed.Remaining())
+	}
+	for original.HasMore() {
+		a, _ := original.NextValue()
+		b, _ := resumed.NextValue()
+		if a != b {
+			t.Fatalf("Expected resumed sequence to draw 0x%02x but got 0x%02x", a, b)
+		}
+	}
+
+	if NewRandomSeqSeededAt(2024, 1000, nil).HasMore() {
+		t.Errorf("Expected replaying past the end to leave the sequence exhausted")
+	}
+}
+
+func TestFromAllowedOnlyReturnsAllowed(t *testing.T) {
+	allowed := []byte("0123456789abcdef")
+	byteSeq := NewRandomSeqFromAllowed(append(allowed, 'a'), WithExcluded('f'))
+
+	var returned [256]int
+	for value := range byteSeq.Values() {
+		returned[value]++
+	}
+
+	for value, count := range returned {
+		expected := 0
+		if bytes.IndexByte(allowed, byte(value)) >= 0 && value != 'f' {
+			expected = 1
+		}
+		if count != expected {
+			t.Errorf("Expected %d occurrences of %q but got %d", expected, value, count)
+		}
+	}
+}
+
+func TestMustNextValuePanicsWhenExhausted(t *testing.T) {
+	byteSeq := NewRandomSeqFromAllowed([]byte{0x42})
+	if value := byteSeq.MustNextValue(); value != 0x42 {
+		t.Errorf("Expected 0x42 but got 0x%02x", value)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected MustNextValue to panic on an exhausted sequence")
+		}
+	}()
+	byteSeq.MustNextValue()
+}
+
+func TestTryNextValue(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	var returnedValueCounts [256]int
+
+	for {
+		value, ok := byteSeq.TryNextValue()
+		if !ok {
+			break
+		}
+		returnedValueCounts[value]++
+	}
+
+	for value, count := range returnedValueCounts {
+		if count != 1 {
+			t.Errorf("Expected one occurrence of 0x%02x but got %d", value, count)
+		}
+	}
+}
+
+func TestNextValueCtxHonoursCancellation(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if _, err := byteSeq.NextValueCtx(ctx); err != nil {
+		t.Fatalf("Expected a draw with a live context to succeed, got %v", err)
+	}
+
+	cancel()
+	if _, err := byteSeq.NextValueCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from a cancelled draw, got %v", err)
+	}
+	if got := byteSeq.Remaining(); got != 255 {
+		t.Errorf("Expected the cancelled draw not to consume a value, but %d remain", got)
+	}
+}
+
+func TestNamedSequencesAgree(t *testing.T) {
+	a := NewRandomSeqNamed("job-42/phase-1", nil).Drain()
+	b := NewRandomSeqNamed("job-42/phase-1", nil).Drain()
+	c := NewRandomSeqNamed("job-42/phase-2", nil).Drain()
+
+	if !bytes.Equal(a, b) {
+		t.Errorf("Expected sequences with the same label to match")
+	}
+	if bytes.Equal(a, c) {
+		t.Errorf("Expected sequences with different labels to differ")
+	}
+	if len(a) != 256 {
+		t.Errorf("Expected 256 values, got %d", len(a))
+	}
+}
+
+func TestZeroValueIsEmpty(t *testing.T) {
+	var byteSeq RandomByteSeq
+
+	if consumed, err := byteSeq.Consume(0x05); consumed || err != nil {
+		t.Errorf("Expected nothing to consume from the zero value, got %v (%v)", consumed, err)
+	}
+	if byteSeq.HasMore() {
+		t.Errorf("Expected the zero value to have nothing to draw")
+	}
+	if _, err := byteSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted from the zero value, got %v", err)
+	}
+
+	// It can still be decoded into
+	data, _ := NewRandomSeq([]byte{0x05}).MarshalBinary()
+	if err := byteSeq.UnmarshalBinary(data); err != nil || byteSeq.Remaining() != 255 {
+		t.Errorf("Expected to decode into the zero value, got %v with %d remaining", err, byteSeq.Remaining())
+	}
+}
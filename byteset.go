@@ -0,0 +1,100 @@
+package byteseq
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// A ByteSet is a set of byte values, held as a 256-bit bitmap. Value b is
+// bit b&7 of byte b>>3, which is the layout a RandomByteSeq uses to track
+// its consumed values. The zero ByteSet is empty and ready to use, and a
+// ByteSet can be copied and compared with == like any array.
+type ByteSet [32]byte
+
+// NewByteSet returns a ByteSet holding the given values.
+func NewByteSet(values ...byte) ByteSet {
+	var s ByteSet
+	for _, b := range values {
+		s.Add(b)
+	}
+	return s
+}
+
+// Add puts b into the set.
+func (s *ByteSet) Add(b byte) {
+	s[b>>3] |= 1 << (b & 0x07)
+}
+
+// Remove takes b out of the set.
+func (s *ByteSet) Remove(b byte) {
+	s[b>>3] &^= 1 << (b & 0x07)
+}
+
+// Contains reports whether b is in the set.
+func (s ByteSet) Contains(b byte) bool {
+	return s[b>>3]&(1<<(b&0x07)) != 0
+}
+
+// Count returns the number of values in the set.
+func (s ByteSet) Count() int {
+	count := 0
+	for _, bitmap := range s {
+		count += bits.OnesCount8(bitmap)
+	}
+	return count
+}
+
+// Union returns the values that are in either s or other.
+func (s ByteSet) Union(other ByteSet) ByteSet {
+	for i := range s {
+		s[i] |= other[i]
+	}
+	return s
+}
+
+// Intersect returns the values that are in both s and other.
+func (s ByteSet) Intersect(other ByteSet) ByteSet {
+	for i := range s {
+		s[i] &= other[i]
+	}
+	return s
+}
+
+// Difference returns the values that are in s but not in other.
+func (s ByteSet) Difference(other ByteSet) ByteSet {
+	for i := range s {
+		s[i] &^= other[i]
+	}
+	return s
+}
+
+// All returns an iterator over the values in the set, in numeric order.
+func (s ByteSet) All() iter.Seq[byte] {
+	return func(yield func(byte) bool) {
+		for i, bitmap := range s {
+			for bitmap != 0 {
+				bit := bits.TrailingZeros8(bitmap)
+				if !yield(byte(i<<3 | bit)) {
+					return
+				}
+				bitmap &= bitmap - 1
+			}
+		}
+	}
+}
+
+// nth returns the i'th smallest value in the set, counting from zero. The
+// set must hold more than i values.
+func (s ByteSet) nth(i int) byte {
+	for j, bitmap := range s {
+		if count := bits.OnesCount8(bitmap); i >= count {
+			i -= count
+			continue
+		}
+		for ; i > 0; i-- {
+			bitmap &= bitmap - 1
+		}
+		return byte(j<<3 | bits.TrailingZeros8(bitmap))
+	}
+	panic("byteseq: ByteSet index out of range")
+}
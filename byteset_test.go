@@ -0,0 +1,85 @@
+package byteseq
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestByteSetAddRemoveContains(t *testing.T) {
+	var s ByteSet
+	s.Add(0x00)
+	s.Add(0x41)
+	s.Add(0xFF)
+	s.Add(0x41)
+
+	if got := s.Count(); got != 3 {
+		t.Errorf("Expected 3 values in the set, got %d", got)
+	}
+	for _, b := range []byte{0x00, 0x41, 0xFF} {
+		if !s.Contains(b) {
+			t.Errorf("Expected the set to contain 0x%02x", b)
+		}
+	}
+	if s.Contains(0x42) {
+		t.Errorf("Expected the set not to contain 0x42")
+	}
+
+	s.Remove(0x41)
+	if s.Contains(0x41) || s.Count() != 2 {
+		t.Errorf("Expected 0x41 to be removed, leaving 2 values")
+	}
+
+	// The layout matches the sequence's consumed bitmap
+	if s[0] != 0x01 || s[31] != 0x80 {
+		t.Errorf("Unexpected bitmap layout: first byte 0x%02x, last byte 0x%02x", s[0], s[31])
+	}
+}
+
+func TestByteSetOperations(t *testing.T) {
+	a := NewByteSet(1, 2, 3, 200)
+	b := NewByteSet(3, 4, 200)
+
+	tests := []struct {
+		name string
+		got  ByteSet
+		want []byte
+	}{
+		{"Union", a.Union(b), []byte{1, 2, 3, 4, 200}},
+		{"Intersect", a.Intersect(b), []byte{3, 200}},
+		{"Difference", a.Difference(b), []byte{1, 2}},
+	}
+	for _, test := range tests {
+		if got := slices.Collect(test.got.All()); !slices.Equal(got, test.want) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+		}
+	}
+
+	// The operands are left alone
+	if got := slices.Collect(a.All()); !slices.Equal(got, []byte{1, 2, 3, 200}) {
+		t.Errorf("Expected the set operations to leave their operand unchanged, got %v", got)
+	}
+}
+
+func TestByteSetAllStopsEarly(t *testing.T) {
+	s := NewByteSet(5, 6, 7)
+	var got []byte
+	for b := range s.All() {
+		got = append(got, b)
+		if b == 6 {
+			break
+		}
+	}
+	if !slices.Equal(got, []byte{5, 6}) {
+		t.Errorf("Expected iteration to stop after 6, got %v", got)
+	}
+}
+
+func TestByteSetNth(t *testing.T) {
+	values := []byte{0, 7, 8, 100, 255}
+	s := NewByteSet(values...)
+	for i, want := range values {
+		if got := s.nth(i); got != want {
+			t.Errorf("Expected value %d to be 0x%02x, got 0x%02x", i, want, got)
+		}
+	}
+}
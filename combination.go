@@ -0,0 +1,121 @@
+package byteseq
+
+import (
+	"errors"
+
+	"github.com/owenjklan/byteseq/perm"
+)
+
+// MaxCombinationSize is the largest subset size a CombinationSeq supports
+// directly. There are too many subsets of 12 to 244 values to count in a
+// uint64, so only sizes up to this, and from 256-MaxCombinationSize up,
+// are allowed.
+const MaxCombinationSize = 11
+
+// ErrInvalidCombinationSize is returned by NewCombinationSeq for subset sizes
+// it can't count.
+var ErrInvalidCombinationSize = errors.New("subset size must be at most 11 or from 245 to 256")
+
+// binomial[n][k] is n choose k, for the sizes a CombinationSeq supports
+var binomial = func() (table [257][MaxCombinationSize + 1]uint64) {
+	for n := range table {
+		table[n][0] = 1
+		for k := 1; k <= MaxCombinationSize && n > 0; k++ {
+			table[n][k] = table[n-1][k-1] + table[n-1][k]
+		}
+	}
+	return table
+}()
+
+// A CombinationSeq returns every k-element subset of the byte values once
+// each, in pseudo-random order. Unlike a TupleSeq, order doesn't matter:
+// each subset is returned as a ByteSet, and {1, 2} is the same subset as
+// {2, 1}. There are 256 choose k of them.
+//
+// Like TupleSeq, it walks a counter through a keyed permutation from
+// package perm and decodes each index into a subset, using the
+// combinatorial number system, so it needs no memory of what it has
+// returned. The same key always gives the same order. It is not safe for
+// concurrent use.
+type CombinationSeq struct {
+	k          int
+	complement bool // decode subsets of 256-k values and invert them
+	size       uint64
+	seq        *perm.Seq
+}
+
+// NewCombinationSeq returns a CombinationSeq over the subsets of k values. k
+// may be up to MaxCombinationSize, or at least 256-MaxCombinationSize, where
+// the subsets are the complements of small ones; other sizes return
+// ErrInvalidCombinationSize. key must be perm.KeySize bytes long; if it is
+// nil, a random key is chosen, giving a different order each time.
+func NewCombinationSeq(k int, key []byte) (*CombinationSeq, error) {
+	c := &CombinationSeq{k: k}
+	if k >= 256-MaxCombinationSize && k <= 256 {
+		c.k, c.complement = 256-k, true
+	}
+	if c.k < 0 || c.k > MaxCombinationSize {
+		return nil, ErrInvalidCombinationSize
+	}
+
+	key, err := keyOrRandom(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.size = binomial[256][c.k]
+	c.seq, err = perm.NewSeq(c.size, key)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Size returns how many subsets the sequence covers in total.
+func (c *CombinationSeq) Size() uint64 {
+	return c.size
+}
+
+// HasMore reports whether the sequence has any more subsets to return.
+func (c *CombinationSeq) HasMore() bool {
+	return c.seq.HasMore()
+}
+
+// Remaining returns how many subsets can still be drawn from the sequence.
+func (c *CombinationSeq) Remaining() uint64 {
+	return c.seq.Remaining()
+}
+
+// NextValue returns a subset that hasn't been returned before, or
+// ErrExhausted once every subset has been returned.
+func (c *CombinationSeq) NextValue() (ByteSet, error) {
+	index, err := c.seq.NextValue()
+	if err != nil {
+		return ByteSet{}, ErrExhausted
+	}
+
+	subset := c.subset(index)
+	if c.complement {
+		for i := range subset {
+			subset[i] = ^subset[i]
+		}
+	}
+	return subset, nil
+}
+
+// subset decodes an index into the subset it stands for: the largest value
+// is the largest v with (v choose k) no more than the index, and so on
+// down with what is left of the index.
+func (c *CombinationSeq) subset(index uint64) ByteSet {
+	var subset ByteSet
+	v := 255
+	for k := c.k; k > 0; k-- {
+		for binomial[v][k] > index {
+			v--
+		}
+		subset.Add(byte(v))
+		index -= binomial[v][k]
+		v--
+	}
+	return subset
+}
@@ -0,0 +1,65 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCombinationSeqCoversEverySubsetOnce(t *testing.T) {
+	for _, k := range []int{0, 1, 2, 254, 256} {
+		combinationSeq, err := NewCombinationSeq(k, testKey)
+		if err != nil {
+			t.Fatalf("k=%d: unexpected error: %v", k, err)
+		}
+
+		seen := make(map[ByteSet]bool)
+		for combinationSeq.HasMore() {
+			subset, _ := combinationSeq.NextValue()
+			if got := subset.Count(); got != k {
+				t.Fatalf("k=%d: subset has %d values", k, got)
+			}
+			if seen[subset] {
+				t.Fatalf("k=%d: subset returned twice", k)
+			}
+			seen[subset] = true
+		}
+
+		if uint64(len(seen)) != combinationSeq.Size() {
+			t.Errorf("k=%d: expected %d subsets, got %d", k, combinationSeq.Size(), len(seen))
+		}
+		if _, err := combinationSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+			t.Errorf("k=%d: expected ErrExhausted but got %v", k, err)
+		}
+	}
+}
+
+func TestCombinationSeqSizes(t *testing.T) {
+	tests := []struct {
+		k    int
+		size uint64
+	}{
+		{2, 32640},
+		{3, 2763520},
+		{MaxCombinationSize, 6235568072914502400},
+		{256 - MaxCombinationSize, 6235568072914502400},
+	}
+	for _, test := range tests {
+		combinationSeq, err := NewCombinationSeq(test.k, testKey)
+		if err != nil {
+			t.Fatalf("k=%d: unexpected error: %v", test.k, err)
+		}
+		if got := combinationSeq.Size(); got != test.size {
+			t.Errorf("k=%d: expected %d subsets, got %d", test.k, test.size, got)
+		}
+		subset, _ := combinationSeq.NextValue()
+		if got := subset.Count(); got != test.k {
+			t.Errorf("k=%d: subset has %d values", test.k, got)
+		}
+	}
+
+	for _, k := range []int{-1, MaxCombinationSize + 1, 128, 256 - MaxCombinationSize - 1, 257} {
+		if _, err := NewCombinationSeq(k, nil); !errors.Is(err, ErrInvalidCombinationSize) {
+			t.Errorf("Expected ErrInvalidCombinationSize for k=%d, got %v", k, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package byteseq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrRangeExhausted is returned by NextInRange when every value in the
+// requested range has been consumed, even though the sequence as a whole may
+// have values left.
+var ErrRangeExhausted = errors.New("no values left in range")
+
+// ErrNoMatch is returned by the constrained draws when no value left in the
+// sequence meets the constraint.
+var ErrNoMatch = errors.New("no unconsumed value matches")
+
+// NextInRange draws a random value from the inclusive range [lo, hi] that
+// hasn't been returned before, for interleaving draws from different
+// classes of value out of one sequence. It returns ErrRangeExhausted if
+// there are none left in the range, which is also the case if lo is greater
+// than hi. The value is chosen uniformly from those left in the range, and
+// is otherwise drawn like any other: it is recorded in the history and
+// passed through the transform.
+func (r *RandomByteSeq) NextInRange(lo, hi byte) (byte, error) {
+	var allowed ByteSet
+	for i := int(lo); i <= int(hi); i++ {
+		allowed.Add(byte(i))
+	}
+
+	_ = r.wait(context.Background())
+
+	r.lock()
+	defer r.unlock()
+
+	value, ok := r.drawFrom(func() ByteSet { return allowed })
+	if !ok {
+		return 0, fmt.Errorf("%w: 0x%02x-0x%02x", ErrRangeExhausted, lo, hi)
+	}
+	return r.output(value, nil)
+}
+
+// NextMatching draws a random value that hasn't been returned before and for
+// which match returns true, such as the next free even-numbered channel. It
+// returns ErrNoMatch if no value left in the sequence matches. match is
+// called once for each value left, and the value is chosen uniformly from
+// those that match, with a single random number rather than drawing and
+// retrying. As with NextInRange, the value is otherwise drawn like any
+// other. match is called with the lock held, so it must not use the
+// sequence.
+func (r *RandomByteSeq) NextMatching(match func(byte) bool) (byte, error) {
+	_ = r.wait(context.Background())
+
+	r.lock()
+	defer r.unlock()
+
+	value, ok := r.drawFrom(func() ByteSet {
+		var allowed ByteSet
+		for _, b := range r.pool[:r.remainingValues] {
+			if match(b) {
+				allowed.Add(b)
+			}
+		}
+		return allowed
+	})
+	if !ok {
+		return 0, ErrNoMatch
+	}
+	return r.output(value, nil)
+}
+
+// NextFromSet draws a random value that hasn't been returned before and is
+// in allowed, so eligibility can be worked out fresh for each draw, for
+// example from the values a peer currently permits. It returns ErrNoMatch
+// if none of the values in allowed are left. Working on the bitmaps alone,
+// it is the cheapest of the constrained draws, and the value is otherwise
+// drawn like any other.
+func (r *RandomByteSeq) NextFromSet(allowed ByteSet) (byte, error) {
+	_ = r.wait(context.Background())
+
+	r.lock()
+	defer r.unlock()
+
+	value, ok := r.drawFrom(func() ByteSet { return allowed })
+	if !ok {
+		return 0, ErrNoMatch
+	}
+	return r.output(value, nil)
+}
+
+// drawFrom draws a random unconsumed value that is also in the set allowed
+// returns, reporting false if there isn't one. It applies the exhaustion
+// policy first, so allowed is only worked out once there are values to
+// draw, and the zero the policy may hand out is reported as drawn. It takes
+// a single random number and no retries, however few of the values are
+// eligible.
+func (r *RandomByteSeq) drawFrom(allowed func() ByteSet) (byte, bool) {
+	zero, err := r.awaitAvailable(context.Background())
+	switch {
+	case err != nil:
+		return 0, false
+	case zero:
+		return 0, true
+	}
+
+	candidates := allowed().Difference(r.consumedBytes)
+	n := candidates.Count()
+	if n == 0 {
+		return 0, false
+	}
+
+	value := r.choose(candidates)
+	if r.hasPeeked && r.peeked == value {
+		r.hasPeeked = false
+	}
+	r.consumeByte(value)
+	r.record(value)
+	return value, true
+}
@@ -0,0 +1,130 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNextInRange(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x13}, WithHistory())
+
+	var seen [256]bool
+	for i := 0; i < 15; i++ {
+		value, err := byteSeq.NextInRange(0x10, 0x1F)
+		if err != nil {
+			t.Fatalf("Unexpected error drawing from the range: %v", err)
+		}
+		if value < 0x10 || value > 0x1F || value == 0x13 {
+			t.Errorf("Got 0x%02x, which is outside the range or consumed", value)
+		}
+		if seen[value] {
+			t.Errorf("Value 0x%02x drawn twice", value)
+		}
+		seen[value] = true
+	}
+
+	if _, err := byteSeq.NextInRange(0x10, 0x1F); !errors.Is(err, ErrRangeExhausted) {
+		t.Errorf("Expected ErrRangeExhausted once the range is used up, got %v", err)
+	}
+	if got := byteSeq.Remaining(); got != 240 {
+		t.Errorf("Expected the rest of the sequence to be untouched, got %d remaining", got)
+	}
+	if got := len(byteSeq.History()); got != 15 {
+		t.Errorf("Expected the range draws to be recorded, got %d entries", got)
+	}
+	if _, err := byteSeq.NextInRange(0x20, 0x10); !errors.Is(err, ErrRangeExhausted) {
+		t.Errorf("Expected ErrRangeExhausted for an inverted range, got %v", err)
+	}
+	checkPool(t, byteSeq)
+}
+
+func TestNextInRangeTakesPeekedValue(t *testing.T) {
+	byteSeq := NewRangeSeq(0x40, 0x40)
+	_, _ = byteSeq.Peek()
+	if value, err := byteSeq.NextInRange(0x00, 0xFF); value != 0x40 || err != nil {
+		t.Fatalf("Expected 0x40, got 0x%02x and %v", value, err)
+	}
+	if _, err := byteSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected the peeked value not to be handed out again, got %v", err)
+	}
+}
+
+func TestNextMatching(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	even := func(b byte) bool { return b%2 == 0 }
+
+	for i := 0; i < 128; i++ {
+		value, err := byteSeq.NextMatching(even)
+		if err != nil {
+			t.Fatalf("Unexpected error after %d even values: %v", i, err)
+		}
+		if value%2 != 0 {
+			t.Errorf("Got odd value 0x%02x", value)
+		}
+	}
+
+	if _, err := byteSeq.NextMatching(even); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch once the even values are used up, got %v", err)
+	}
+	if got := byteSeq.Remaining(); got != 128 {
+		t.Errorf("Expected the 128 odd values to be left, got %d", got)
+	}
+	checkPool(t, byteSeq)
+}
+
+func TestNextFromSet(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x02})
+	allowed := NewByteSet(0x01, 0x02, 0x03)
+
+	var got ByteSet
+	for i := 0; i < 2; i++ {
+		value, err := byteSeq.NextFromSet(allowed)
+		if err != nil {
+			t.Fatalf("Unexpected error drawing from the set: %v", err)
+		}
+		got.Add(value)
+	}
+
+	if got != NewByteSet(0x01, 0x03) {
+		t.Errorf("Expected to draw 0x01 and 0x03, got %v", got)
+	}
+	if _, err := byteSeq.NextFromSet(allowed); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch once the set is used up, got %v", err)
+	}
+	if _, err := byteSeq.NextFromSet(ByteSet{}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Expected ErrNoMatch for an empty set, got %v", err)
+	}
+}
+
+func TestConstrainedDrawsApplyExhaustionPolicy(t *testing.T) {
+	cycling := NewRangeSeq(0, 3, WithCycle())
+	cycling.Drain()
+	if _, err := cycling.NextInRange(0, 3); err != nil {
+		t.Fatalf("Expected a new epoch, got %v", err)
+	}
+	if got := cycling.Epoch(); got != 1 {
+		t.Errorf("Expected epoch 1, got %d", got)
+	}
+
+	zeroing := NewRangeSeq(10, 13, WithExhaustionPolicy(ExhaustZero))
+	zeroing.Drain()
+	if value, err := zeroing.NextMatching(func(byte) bool { return true }); value != 0 || err != nil {
+		t.Errorf("Expected a zero once exhausted, got 0x%02x (%v)", value, err)
+	}
+
+	// A constraint that misses the values left is still an error
+	if _, err := NewRangeSeq(10, 13, WithExhaustionPolicy(ExhaustZero)).NextInRange(0, 3); !errors.Is(err, ErrRangeExhausted) {
+		t.Errorf("Expected ErrRangeExhausted, got %v", err)
+	}
+
+	blocking := NewRangeSeq(0, 3, WithExhaustionPolicy(ExhaustBlock))
+	values := blocking.Drain()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		blocking.Release(values[2])
+	}()
+	if value, err := blocking.NextFromSet(NewByteSet(values[2])); value != values[2] || err != nil {
+		t.Errorf("Expected to wait for 0x%02x, got 0x%02x (%v)", values[2], value, err)
+	}
+}
@@ -0,0 +1,34 @@
+package byteseq
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+)
+
+// CryptoSource is a Source that reads from crypto/rand. Sequences drawing
+// from it have an unpredictable order, which makes them suitable when the
+// values end up in security-sensitive material. Unlike the other sources it
+// is safe for concurrent use.
+type CryptoSource struct{}
+
+// Uint64 returns a uniformly distributed value read from crypto/rand. It
+// panics if the operating system's random number generator fails, as there
+// is no sensible way to carry on without it.
+func (CryptoSource) Uint64() uint64 {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic("byteseq: reading from crypto/rand failed: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// NewCryptoRandomSeq returns a new RandomByteSeq that draws all of its values
+// from crypto/rand, via CryptoSource.
+func NewCryptoRandomSeq(consumedBytes []byte) *RandomByteSeq {
+	return NewRandomSeq(consumedBytes, WithCryptoRand())
+}
+
+// WithCryptoRand makes the sequence draw from crypto/rand, via CryptoSource.
+func WithCryptoRand() Option {
+	return WithSource(CryptoSource{})
+}
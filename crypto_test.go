@@ -0,0 +1,25 @@
+package byteseq
+
+import (
+	"testing"
+)
+
+func TestCryptoSequenceCoversAllValues(t *testing.T) {
+	byteSeq := NewCryptoRandomSeq([]byte{0x00, 0xFF})
+	var returnedValueCounts [256]int
+
+	for byteSeq.HasMore() {
+		value, _ := byteSeq.NextValue()
+		returnedValueCounts[value]++
+	}
+
+	for value, count := range returnedValueCounts {
+		expected := 1
+		if value == 0x00 || value == 0xFF {
+			expected = 0
+		}
+		if count != expected {
+			t.Errorf("Expected %d occurrences of 0x%02x but got %d", expected, value, count)
+		}
+	}
+}
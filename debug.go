@@ -0,0 +1,173 @@
+package byteseq
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// String returns a one-line summary of the sequence for logs and %v: how
+// many values remain, the epoch, and the kind of random source along with a
+// fingerprint of its state, where it can be had. Two sequences with the same
+// fingerprint and the same values remaining draw the same values, which
+// helps in checking that a seeded run really is being repeated.
+//
+//	byteseq.RandomByteSeq{remaining: 253/256, epoch: 0, source: pcg#1a2b3c4d}
+func (r *RandomByteSeq) String() string {
+	r.lock()
+	defer r.unlock()
+
+	return fmt.Sprintf("byteseq.RandomByteSeq{remaining: %d/%d, epoch: %d, source: %s}",
+		r.remainingValues, r.excludedRemaining, r.epoch, r.describeSource())
+}
+
+// DumpState writes a full, human-readable description of the sequence's
+// state to w, for debugging: the counts, the settings it was created with,
+// and which values are consumed, excluded, reserved and still to be drawn.
+// Runs of consecutive values are shown as ranges. The layout is meant for
+// people and may change; use MarshalBinary or MarshalJSON for anything a
+// program needs to read back.
+func (r *RandomByteSeq) DumpState(w io.Writer) error {
+	r.lock()
+	defer r.unlock()
+
+	var b strings.Builder
+	line := func(label, format string, args ...any) {
+		fmt.Fprintf(&b, "  %-11s "+format+"\n", append([]any{label + ":"}, args...)...)
+	}
+
+	b.WriteString("byteseq.RandomByteSeq\n")
+	line("remaining", "%d of %d (%d excluded)", r.remainingValues, r.excludedRemaining, 256-r.excludedRemaining)
+	line("epoch", "%d", r.epoch)
+	line("source", "%s", r.describeSource())
+	line("policy", "%v", r.policy)
+	line("locking", "%t", r.mu != nil)
+	line("settings", "%s", r.describeSettings())
+	if r.hasPeeked {
+		line("peeked", "0x%02x", r.peeked)
+	}
+	if r.keepHistory {
+		line("history", "%d values drawn", len(r.history))
+	}
+	if r.leases != nil {
+		held := 0
+		for _, expiry := range r.leases.expiry {
+			if !expiry.IsZero() {
+				held++
+			}
+		}
+		line("leases", "%d held, %v ttl", held, r.leases.ttl)
+	}
+	line("excluded", "%s", formatSet(r.excludedBytes))
+	line("consumed", "%s", formatSet(r.consumedBytes.Difference(r.excludedBytes)))
+	line("reserved", "%s", formatSet(r.reserved))
+	line("unconsumed", "%s", formatSet(r.unconsumed()))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderGrid draws the sequence's values to w as a 16 by 16 grid, one row
+// for each high nibble and one column for each low nibble, so coverage can
+// be seen at a glance. Values still to be drawn are shown as '.', consumed
+// values as '#', and values excluded at construction as 'x':
+//
+//	   0123456789abcdef
+//	00 x#..#.##..#.#...
+//	10 .#.#..##........
+//	...
+func (r *RandomByteSeq) RenderGrid(w io.Writer) error {
+	r.lock()
+	defer r.unlock()
+
+	var b strings.Builder
+	b.WriteString("   0123456789abcdef\n")
+	for row := 0; row < 16; row++ {
+		fmt.Fprintf(&b, "%02x ", row<<4)
+		for col := 0; col < 16; col++ {
+			value := byte(row<<4 | col)
+			switch {
+			case r.isExcluded(value):
+				b.WriteByte('x')
+			case r.valueHasBeenConsumed(value):
+				b.WriteByte('#')
+			default:
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describeSource names the kind of random source, with a short fingerprint
+// of its state if it can be marshalled
+func (r *RandomByteSeq) describeSource() string {
+	name := sourceNames[sourceKind(r.src)]
+	m, ok := r.src.(encoding.BinaryMarshaler)
+	if !ok {
+		return name
+	}
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return name
+	}
+	sum := sha256.Sum256(state)
+	return fmt.Sprintf("%s#%x", name, sum[:4])
+}
+
+// describeSettings lists the options that change how values are drawn
+func (r *RandomByteSeq) describeSettings() string {
+	var settings []string
+	add := func(set bool, name string) {
+		if set {
+			settings = append(settings, name)
+		}
+	}
+	add(r.weights != nil, "weights")
+	add(r.limiter != nil, "rate limit")
+	add(r.transform != nil, "transform")
+	if r.ordering != nil {
+		settings = append(settings, fmt.Sprintf("ordering %T", r.ordering))
+	}
+	add(len(r.middleware) > 0, "middleware")
+	add(len(r.watermarks) > 0, "watermarks")
+	add(len(r.onConsume) > 0 || len(r.onExhausted) > 0, "hooks")
+	add(r.logger != nil, "logger")
+
+	if len(settings) == 0 {
+		return "none"
+	}
+	return strings.Join(settings, ", ")
+}
+
+// formatSet lists the values in s, with runs of consecutive values shown as
+// ranges, such as "0x00-0x0f 0x20"
+func formatSet(s ByteSet) string {
+	var parts []string
+	for i := 0; i < 256; {
+		if !s.Contains(byte(i)) {
+			i++
+			continue
+		}
+		j := i
+		for j < 255 && s.Contains(byte(j+1)) {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprintf("0x%02x", i))
+		} else {
+			parts = append(parts, fmt.Sprintf("0x%02x-0x%02x", i, j))
+		}
+		i = j + 1
+	}
+
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,89 @@
+package byteseq
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestStringSummary(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{1, 2}, WithSource(rand.NewPCG(1, 2)))
+	_, _ = byteSeq.NextValue()
+
+	s := fmt.Sprintf("%+v", byteSeq)
+	if !strings.HasPrefix(s, "byteseq.RandomByteSeq{remaining: 253/254, epoch: 0, source: pcg#") {
+		t.Errorf("Unexpected summary %q", s)
+	}
+
+	// The fingerprint follows the source's state
+	same := NewRandomSeq([]byte{1, 2}, WithSource(rand.NewPCG(1, 2)))
+	_, _ = same.NextValue()
+	if same.String() != s {
+		t.Errorf("Expected matching sequences to match, got %q and %q", same.String(), s)
+	}
+	_, _ = same.NextValue()
+	if strings.HasSuffix(same.String(), s[strings.Index(s, "#"):]) {
+		t.Errorf("Expected the fingerprint to change with the source, got %q twice", same.String())
+	}
+
+	if s := NewRandomSeq(nil).String(); !strings.HasSuffix(s, "source: global}") {
+		t.Errorf("Unexpected summary for the global source %q", s)
+	}
+}
+
+func TestDumpState(t *testing.T) {
+	byteSeq := NewRangeSeq(0x10, 0x1f, WithHistory(), WithOrdering(Sequential()))
+	_, _ = byteSeq.NextN(3)
+	reserved, _ := byteSeq.Reserve()
+	_, _ = byteSeq.Peek()
+
+	var b strings.Builder
+	if err := byteSeq.DumpState(&b); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	dump := b.String()
+	for _, want := range []string{
+		"remaining:  12 of 16 (240 excluded)",
+		"settings:   ordering byteseq.sequentialOrdering",
+		"peeked:     0x14",
+		"history:    4 values drawn",
+		"excluded:   0x00-0x0f 0x20-0xff",
+		"consumed:   0x10-0x13",
+		fmt.Sprintf("reserved:   0x%02x", reserved),
+		"unconsumed: 0x14-0x1f",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("Expected the dump to contain %q, got\n%s", want, dump)
+		}
+	}
+}
+
+func TestRenderGrid(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00, 0xff})
+	_, _ = byteSeq.Consume(0x01)
+	_, _ = byteSeq.Consume(0x1f)
+	_, _ = byteSeq.Consume(0xf0)
+
+	var b strings.Builder
+	if err := byteSeq.RenderGrid(&b); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 17 {
+		t.Fatalf("Expected a header and 16 rows, got %d lines", len(lines))
+	}
+
+	expected := map[int]string{
+		0:  "   0123456789abcdef",
+		1:  "00 x#..............",
+		2:  "10 ...............#",
+		3:  "20 ................",
+		16: "f0 #..............x",
+	}
+	for i, want := range expected {
+		if lines[i] != want {
+			t.Errorf("Expected line %d to be %q, got %q", i, want, lines[i])
+		}
+	}
+}
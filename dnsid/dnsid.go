@@ -0,0 +1,164 @@
+// Package dnsid hands out DNS query IDs, and other 16-bit transaction IDs,
+// the way resolvers should: at random, so responses are hard to spoof, and
+// never reusing an ID while a query using it is still in flight.
+//
+// A Tracker draws each ID from an idalloc.Allocator and keeps it
+// outstanding until the response arrives and Done is called, or until the
+// query times out, at which point the ID is freed for reuse.
+package dnsid
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/owenjklan/byteseq/idalloc"
+)
+
+// DefaultTimeout is how long a Tracker keeps an ID outstanding when no
+// timeout is set with WithTimeout. It matches the usual resolver timeout.
+const DefaultTimeout = 5 * time.Second
+
+// ErrExhausted is returned by Next when all 65536 IDs are outstanding.
+var ErrExhausted = errors.New("all query IDs are outstanding")
+
+// A Source is the minimal random number generator a Tracker needs. It is
+// the same as rand.Source from math/rand/v2 and byteseq.Source.
+type Source = idalloc.Source
+
+// An Option configures a Tracker.
+type Option func(*Tracker)
+
+// WithTimeout sets how long an ID stays outstanding if Done isn't called
+// for it. A timeout of zero or less keeps IDs outstanding until Done.
+func WithTimeout(timeout time.Duration) Option {
+	return func(t *Tracker) {
+		t.timeout = timeout
+	}
+}
+
+// WithSource makes the Tracker draw IDs from src instead of the
+// package-global source. Use a cryptographically secure source, such as
+// byteseq.CryptoSource, where spoofing is a concern.
+func WithSource(src Source) Option {
+	return func(t *Tracker) {
+		t.src = src
+	}
+}
+
+// OnTimeout calls fn with each ID whose query times out, after the ID has
+// been freed, so the caller can give up on the query. fn is called on a
+// goroutine of its own and may use the Tracker.
+func OnTimeout(fn func(id uint16)) Option {
+	return func(t *Tracker) {
+		t.onTimeout = fn
+	}
+}
+
+// A Tracker hands out random query IDs and keeps track of those that are
+// outstanding. It is safe for concurrent use.
+type Tracker struct {
+	timeout   time.Duration
+	src       Source
+	onTimeout func(id uint16)
+
+	alloc       *idalloc.Allocator[uint16]
+	mu          sync.Mutex
+	outstanding map[uint16]*query
+}
+
+// query is an outstanding ID's entry, telling apart successive uses of the
+// same ID.
+type query struct {
+	timer *time.Timer // nil when there's no timeout
+}
+
+// New returns a Tracker with no IDs outstanding.
+func New(opts ...Option) *Tracker {
+	t := &Tracker{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.alloc = idalloc.NewFromSource[uint16](t.src, idalloc.Random)
+	t.outstanding = make(map[uint16]*query)
+	return t
+}
+
+// Next returns a random ID that isn't outstanding, and marks it outstanding
+// until Done is called for it or it times out. It returns ErrExhausted if
+// every ID is outstanding.
+func (t *Tracker) Next() (uint16, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id, err := t.alloc.Allocate()
+	if err != nil {
+		return 0, ErrExhausted
+	}
+
+	q := &query{}
+	if t.timeout > 0 {
+		q.timer = time.AfterFunc(t.timeout, func() { t.expire(id, q) })
+	}
+	t.outstanding[id] = q
+	return id, nil
+}
+
+// Done marks id as no longer outstanding, typically because its response
+// has arrived, so it can be handed out again. It reports whether id was
+// outstanding; a response with an ID that isn't outstanding is either late
+// or forged, and should be dropped.
+func (t *Tracker) Done(id uint16) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	q, ok := t.outstanding[id]
+	if !ok {
+		return false
+	}
+	if q.timer != nil {
+		q.timer.Stop()
+	}
+	t.release(id)
+	return true
+}
+
+// IsOutstanding reports whether id has been handed out and is still waiting
+// for its response.
+func (t *Tracker) IsOutstanding(id uint16) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.outstanding[id]
+	return ok
+}
+
+// Outstanding returns how many IDs are outstanding.
+func (t *Tracker) Outstanding() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.outstanding)
+}
+
+// expire frees id when its timer fires, unless the ID has been handed back
+// with Done, and perhaps handed out again with a new timer, in the meantime.
+func (t *Tracker) expire(id uint16, q *query) {
+	t.mu.Lock()
+	if t.outstanding[id] != q {
+		t.mu.Unlock()
+		return
+	}
+	t.release(id)
+	t.mu.Unlock()
+
+	if t.onTimeout != nil {
+		t.onTimeout(id)
+	}
+}
+
+// release frees id. The lock must be held.
+func (t *Tracker) release(id uint16) {
+	delete(t.outstanding, id)
+	t.alloc.Free(id)
+}
@@ -0,0 +1,69 @@
+package dnsid
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestNextNeverReusesOutstandingIDs(t *testing.T) {
+	tracker := New(WithTimeout(0), WithSource(rand.NewPCG(1, 2)))
+
+	seen := make(map[uint16]bool)
+	for i := 0; i < 1<<16; i++ {
+		id, err := tracker.Next()
+		if err != nil || seen[id] {
+			t.Fatalf("Unexpected ID %d (%v)", id, err)
+		}
+		seen[id] = true
+	}
+	if _, err := tracker.Next(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+
+	if !tracker.Done(1234) || tracker.Done(1234) {
+		t.Errorf("Expected only the first Done for 1234 to succeed")
+	}
+	if id, _ := tracker.Next(); id != 1234 {
+		t.Errorf("Expected the finished ID 1234 back, got %d", id)
+	}
+}
+
+func TestDoneStopsTracking(t *testing.T) {
+	tracker := New()
+	id, _ := tracker.Next()
+
+	if !tracker.IsOutstanding(id) || tracker.Outstanding() != 1 {
+		t.Errorf("Expected %d to be outstanding", id)
+	}
+	if !tracker.Done(id) {
+		t.Errorf("Expected Done for %d to succeed", id)
+	}
+	if tracker.IsOutstanding(id) || tracker.Outstanding() != 0 {
+		t.Errorf("Expected nothing outstanding after Done")
+	}
+	if tracker.Done(id ^ 1) {
+		t.Errorf("Expected Done for an ID never handed out to fail")
+	}
+}
+
+func TestTimeoutFreesID(t *testing.T) {
+	timedOut := make(chan uint16, 1)
+	tracker := New(WithTimeout(time.Millisecond), OnTimeout(func(id uint16) {
+		timedOut <- id
+	}))
+	id, _ := tracker.Next()
+
+	select {
+	case got := <-timedOut:
+		if got != id {
+			t.Errorf("Expected %d to time out, got %d", id, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for the query to time out")
+	}
+	if tracker.IsOutstanding(id) || tracker.Done(id) {
+		t.Errorf("Expected a timed out ID to be freed")
+	}
+}
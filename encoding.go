@@ -0,0 +1,518 @@
+package byteseq
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/rand/v2"
+)
+
+// ErrInvalidState is returned when decoding sequence state that is
+// truncated or inconsistent.
+var ErrInvalidState = errors.New("invalid sequence state")
+
+// ErrChecksum is returned when decoding sequence state whose checksum
+// doesn't match, which means it was damaged in storage or transit. It is
+// kept apart from ErrInvalidState so corruption can be told from state that
+// was written wrongly; either way, the state must not be used, as a
+// sequence restored from it could hand out values twice.
+var ErrChecksum = errors.New("sequence state checksum mismatch")
+
+// ErrUnsupportedVersion is returned when decoding sequence state written in
+// a format version this version of the package doesn't know, typically by a
+// newer release. State written by older releases can always be decoded.
+var ErrUnsupportedVersion = errors.New("unsupported sequence state version")
+
+// Check the sequence keeps satisfying the encoding interfaces
+var (
+	_ encoding.BinaryMarshaler   = (*RandomByteSeq)(nil)
+	_ encoding.BinaryUnmarshaler = (*RandomByteSeq)(nil)
+	_ json.Marshaler             = (*RandomByteSeq)(nil)
+	_ json.Unmarshaler           = (*RandomByteSeq)(nil)
+	_ gob.GobEncoder             = (*RandomByteSeq)(nil)
+	_ gob.GobDecoder             = (*RandomByteSeq)(nil)
+	_ encoding.TextMarshaler     = (*RandomByteSeq)(nil)
+	_ encoding.TextUnmarshaler   = (*RandomByteSeq)(nil)
+)
+
+// Register the type so sequences can also travel as interface values
+func init() {
+	gob.Register(&RandomByteSeq{})
+}
+
+// Kinds of random source recorded in the state, so the right generator can
+// be recreated when decoding into an empty sequence. The binary form stores
+// the index, the JSON form the name.
+const (
+	sourceGlobal byte = iota
+	sourcePCG
+	sourceChaCha8
+	sourceCrypto
+	sourceCustom
+)
+
+var sourceNames = []string{"global", "pcg", "chacha8", "crypto", "custom"}
+
+// The version of the state format written by this package. Decoding
+// accepts this version and any earlier one, so state saved by an older
+// release keeps working after an upgrade. When the format changes, the
+// version goes up and decoding of the old layout stays.
+//
+// Version 1 puts a version in front of the original layout, and version 2
+// adds the checksum. State from before the format was versioned decodes as
+// version 1: the JSON form simply has no version field, and the binary form
+// has no version byte, which UnmarshalBinary recognises.
+const stateVersion = 2
+
+// Table for the CRC-32C checksum over the encoded state
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Size of the checksum at the end of the binary state
+const checksumSize = 4
+
+// Size of the fixed part of the binary state after the version: both bitmaps
+// and the count
+const stateFixedSize = 32 + 32 + 2
+
+// Size of the fixed part of the binary state, including the version
+const stateHeaderSize = 1 + stateFixedSize
+
+// seqState is everything needed to carry on with a sequence later, in a
+// form shared by all of the encodings.
+type seqState struct {
+	Version     int    `json:"version"`
+	Remaining   int    `json:"remaining"`
+	Consumed    []byte `json:"consumed"` // bitmap
+	Excluded    []byte `json:"excluded"` // bitmap
+	Order       []byte `json:"order"`    // the remaining values, in pool order
+	Source      string `json:"source"`
+	SourceState []byte `json:"sourceState,omitempty"`
+	Checksum    uint32 `json:"checksum,omitempty"` // of the binary form, from version 2
+}
+
+// exportState captures the sequence's state. The lock must be held.
+func (r *RandomByteSeq) exportState() (seqState, error) {
+	state := seqState{
+		Version:   stateVersion,
+		Remaining: r.remainingValues,
+		Consumed:  append([]byte(nil), r.consumedBytes[:]...),
+		Excluded:  append([]byte(nil), r.excludedBytes[:]...),
+		Order:     append([]byte(nil), r.pool[:r.remainingValues]...),
+	}
+
+	state.Source = sourceNames[sourceKind(r.src)]
+
+	if m, ok := r.src.(encoding.BinaryMarshaler); ok {
+		srcState, err := m.MarshalBinary()
+		if err != nil {
+			return seqState{}, err
+		}
+		state.SourceState = srcState
+	}
+	return state, nil
+}
+
+// importState replaces the sequence's state, after checking it is
+// consistent. The receiver is left unchanged on error. The lock must be
+// held.
+func (r *RandomByteSeq) importState(state seqState) error {
+	if err := checkVersion(state.Version); err != nil {
+		return err
+	}
+	if len(state.Consumed) != 32 || len(state.Excluded) != 32 {
+		return fmt.Errorf("%w: bitmaps must be 32 bytes", ErrInvalidState)
+	}
+	if state.Remaining < 0 || state.Remaining > 256 || len(state.Order) != state.Remaining {
+		return fmt.Errorf("%w: bad remaining count %d", ErrInvalidState, state.Remaining)
+	}
+
+	next := *r
+	copy(next.consumedBytes[:], state.Consumed)
+	copy(next.excludedBytes[:], state.Excluded)
+	next.remainingValues = state.Remaining
+	if err := next.checkBitmaps(); err != nil {
+		return err
+	}
+	if err := next.restorePool(state.Order); err != nil {
+		return err
+	}
+
+	kind := -1
+	for i, name := range sourceNames {
+		if name == state.Source {
+			kind = i
+		}
+	}
+	if kind < 0 {
+		return fmt.Errorf("%w: unknown source %q", ErrInvalidState, state.Source)
+	}
+	src, err := restoreSource(next.src, byte(kind), state.SourceState)
+	if err != nil {
+		return err
+	}
+	next.src = src
+	next.hasPeeked = false
+	next.reserved = ByteSet{}
+	next.history = nil
+	next.epoch = 0
+	next.exhaustedPending = 0
+	next.resetLeases()
+
+	*r = next
+	r.checkWatermarks()
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, capturing everything
+// needed to carry on with the sequence later: the consumed values, the
+// original exclusions used by Reset, the order of the remaining values and,
+// where the source supports it, the state of the random source. The PCG
+// and ChaCha8 generators from math/rand/v2 do, so a seeded sequence that is
+// restored draws exactly the values it would have drawn. Values held by
+// Reserve are saved as consumed, as though they had been confirmed. A
+// candidate chosen by Peek is not part of the state, and neither are
+// settings made with options such as WithWeights; decode into a sequence
+// created with the same options to keep them. The history kept by
+// WithHistory and the epoch aren't saved either, and decoding clears them,
+// as Reset does, so Undo can't give back values the decoded state consumed.
+//
+// The state starts with a format version, so that state saved now can still
+// be decoded by later releases of the package, and ends with a CRC-32C
+// checksum, so that state damaged in storage is caught rather than quietly
+// producing duplicates. State from a release newer
+// than this one may not be, and is rejected with an error wrapping
+// ErrUnsupportedVersion.
+func (r *RandomByteSeq) MarshalBinary() ([]byte, error) {
+	r.lock()
+	defer r.unlock()
+
+	state, err := r.exportState()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, stateHeaderSize+state.Remaining+1+len(state.SourceState)+checksumSize)
+	data = appendState(data, state)
+	data = binary.BigEndian.AppendUint32(data, crc32.Checksum(data, checksumTable))
+	return data, nil
+}
+
+// appendState appends the binary form of state to data, without the
+// checksum
+func appendState(data []byte, state seqState) []byte {
+	data = append(data, byte(state.Version))
+	data = append(data, state.Consumed...)
+	data = append(data, state.Excluded...)
+	data = binary.BigEndian.AppendUint16(data, uint16(state.Remaining))
+	data = append(data, state.Order...)
+	for i, name := range sourceNames {
+		if name == state.Source {
+			data = append(data, byte(i))
+		}
+	}
+	return append(data, state.SourceState...)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring state
+// written by MarshalBinary. It can be used on a zero RandomByteSeq, in which
+// case the random source is recreated if it was one of the math/rand/v2
+// generators or CryptoSource. For any other Source, the receiver's own
+// source is kept, and is handed the saved source state if it implements
+// encoding.BinaryUnmarshaler. Data that fails its checksum is rejected with
+// an error wrapping ErrChecksum, and inconsistent data with one wrapping
+// ErrInvalidState, leaving the receiver unchanged. State written before the
+// format was versioned, which has neither a version nor a checksum, is
+// decoded too.
+func (r *RandomByteSeq) UnmarshalBinary(data []byte) error {
+	r.lock()
+	defer r.unlock()
+
+	state, err := parseVersioned(data)
+	if err == nil {
+		err = r.importState(state)
+	}
+	if err != nil {
+		// Unversioned state is otherwise laid out as version 1. The count
+		// has to agree with the consumed bitmap, and shifting the layout by
+		// the version byte always breaks that, so state that is consistent
+		// in one layout never is in the other, and trying the old one once
+		// the new one fails can't misread anything.
+		if old, oldErr := parseState(data, 1); oldErr == nil && r.importState(old) == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// parseVersioned splits up binary state with a version in front, checking
+// the version and, from version 2, the checksum
+func parseVersioned(data []byte) (seqState, error) {
+	if len(data) == 0 {
+		return seqState{}, fmt.Errorf("%w: no data", ErrInvalidState)
+	}
+	if err := checkVersion(int(data[0])); err != nil {
+		return seqState{}, err
+	}
+	if data[0] >= 2 {
+		if len(data) < stateHeaderSize+checksumSize {
+			return seqState{}, fmt.Errorf("%w: %d bytes is too short", ErrInvalidState, len(data))
+		}
+		sum := binary.BigEndian.Uint32(data[len(data)-checksumSize:])
+		data = data[:len(data)-checksumSize]
+		if crc32.Checksum(data, checksumTable) != sum {
+			return seqState{}, ErrChecksum
+		}
+	}
+	return parseState(data[1:], int(data[0]))
+}
+
+// parseState splits up binary state that follows the version, without the
+// checksum
+func parseState(data []byte, version int) (seqState, error) {
+	if len(data) < stateFixedSize {
+		return seqState{}, fmt.Errorf("%w: %d bytes is too short", ErrInvalidState, len(data))
+	}
+
+	state := seqState{
+		Version:   version,
+		Consumed:  data[0:32],
+		Excluded:  data[32:64],
+		Remaining: int(binary.BigEndian.Uint16(data[64:66])),
+	}
+	data = data[stateFixedSize:]
+
+	if state.Remaining > 256 || len(data) < state.Remaining+1 {
+		return seqState{}, fmt.Errorf("%w: bad remaining count %d", ErrInvalidState, state.Remaining)
+	}
+	state.Order = data[:state.Remaining]
+	data = data[state.Remaining:]
+
+	if int(data[0]) >= len(sourceNames) {
+		return seqState{}, fmt.Errorf("%w: unknown source kind %d", ErrInvalidState, data[0])
+	}
+	state.Source = sourceNames[data[0]]
+	state.SourceState = data[1:]
+
+	return state, nil
+}
+
+// MarshalJSON implements json.Marshaler. The state is the same as the
+// binary form, laid out as an object so it can be inspected by eye:
+//
+//	{"version":2,"remaining":3,"consumed":"...","excluded":"...","order":"...","source":"pcg","sourceState":"...","checksum":1234}
+//
+// The bitmaps, the order of the remaining values and the source state are
+// base64 encoded, as is usual for bytes in JSON. The checksum is the one the
+// binary form would carry.
+func (r *RandomByteSeq) MarshalJSON() ([]byte, error) {
+	r.lock()
+	defer r.unlock()
+
+	state, err := r.exportState()
+	if err != nil {
+		return nil, err
+	}
+	state.Checksum = crc32.Checksum(appendState(nil, state), checksumTable)
+	return json.Marshal(state)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, restoring state written by
+// MarshalJSON. The random source and the checksum are handled as for
+// UnmarshalBinary.
+func (r *RandomByteSeq) UnmarshalJSON(data []byte) error {
+	r.lock()
+	defer r.unlock()
+
+	// JSON written before the format was versioned has no version, and
+	// is the same as version 1
+	state := seqState{Version: 1}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	if err := checkVersion(state.Version); err != nil {
+		return err
+	}
+	if state.Version >= 2 && crc32.Checksum(appendState(nil, state), checksumTable) != state.Checksum {
+		return ErrChecksum
+	}
+	return r.importState(state)
+}
+
+// GobEncode implements gob.GobEncoder, so a sequence sent over an
+// encoding/gob stream arrives with its state intact. It uses the binary form
+// from MarshalBinary.
+func (r *RandomByteSeq) GobEncode() ([]byte, error) {
+	return r.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder, restoring state written by GobEncode.
+func (r *RandomByteSeq) GobDecode(data []byte) error {
+	return r.UnmarshalBinary(data)
+}
+
+// MarshalText implements encoding.TextMarshaler, giving the binary form from
+// MarshalBinary as a single hex string. It drops cleanly into YAML or TOML
+// configs and log lines.
+func (r *RandomByteSeq) MarshalText() ([]byte, error) {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return hex.AppendEncode(nil, data), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, restoring state written
+// by MarshalText.
+func (r *RandomByteSeq) UnmarshalText(text []byte) error {
+	data, err := hex.AppendDecode(nil, text)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	return r.UnmarshalBinary(data)
+}
+
+// Token returns the sequence's state as a compact, URL-safe string, for
+// carrying a sequence's position in a cookie or query parameter between
+// stateless requests. It is the binary form from MarshalBinary in unpadded
+// URL-safe base64, so it holds the consumed values, the exclusions, the
+// order of the remaining values and the state of the random source, and is
+// at most a few hundred characters long. Restore it with FromToken.
+//
+// The token is not encrypted or signed. Anyone holding it can see which
+// values have been drawn and predict a seeded sequence's coming draws, and
+// a client can hand back a doctored token, so sign or encrypt it if that
+// matters.
+func (r *RandomByteSeq) Token() (string, error) {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// FromToken returns a new sequence in the state captured by Token. As with
+// UnmarshalBinary, settings made with options aren't part of the token, so
+// the sequence is created with opts, which should match those of the
+// original. A token that can't be decoded, or whose state is inconsistent,
+// is rejected with an error wrapping ErrInvalidState, and a bad option with
+// one wrapping ErrInvalidOption.
+func FromToken(token string, opts ...Option) (*RandomByteSeq, error) {
+	cfg := newConfig(opts)
+	if cfg.err != nil {
+		return nil, cfg.err
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+	}
+	seq := newSeq(nil, cfg)
+	if err := seq.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
+
+// checkVersion makes sure state in the given format version can be decoded
+func checkVersion(version int) error {
+	if version < 1 || version > stateVersion {
+		return fmt.Errorf("%w: version %d, expected 1 to %d", ErrUnsupportedVersion, version, stateVersion)
+	}
+	return nil
+}
+
+// checkBitmaps makes sure the consumed count agrees with the bitmap, and
+// that the exclusions are all consumed.
+func (r *RandomByteSeq) checkBitmaps() error {
+	if r.excludedBytes.Difference(r.consumedBytes) != (ByteSet{}) {
+		return fmt.Errorf("%w: excluded values are not consumed", ErrInvalidState)
+	}
+
+	consumed := r.consumedBytes.Count()
+	if consumed != 256-r.remainingValues {
+		return fmt.Errorf("%w: %d consumed values but %d remaining", ErrInvalidState, consumed, r.remainingValues)
+	}
+	r.excludedRemaining = 256 - r.excludedBytes.Count()
+	return nil
+}
+
+// restorePool lays out the pool with the unconsumed values in the given
+// order, followed by the consumed ones.
+func (r *RandomByteSeq) restorePool(order []byte) error {
+	r.rebuildPool()
+	for i, b := range order {
+		if r.valueHasBeenConsumed(b) || int(r.position[b]) < i {
+			return fmt.Errorf("%w: bad pool entry 0x%02x", ErrInvalidState, b)
+		}
+		r.swapInPool(b, r.pool[i])
+	}
+	return nil
+}
+
+// sourceKind returns the kind of random source src is
+func sourceKind(src Source) byte {
+	switch src.(type) {
+	case nil:
+		return sourceGlobal
+	case *rand.PCG:
+		return sourcePCG
+	case *rand.ChaCha8:
+		return sourceChaCha8
+	case CryptoSource:
+		return sourceCrypto
+	}
+	return sourceCustom
+}
+
+// restoreSource recreates the random source recorded in the state, reusing
+// the current one where possible.
+func restoreSource(current Source, kind byte, state []byte) (Source, error) {
+	var src Source
+	switch kind {
+	case sourceGlobal, sourceCrypto:
+		// Neither has any state of its own, so anything left over means the
+		// layout has been misread, such as version 2 state with the version
+		// changed to 1 taking the checksum for source state
+		if len(state) != 0 {
+			return nil, fmt.Errorf("%w: %d bytes of state for the %s source", ErrInvalidState, len(state), sourceNames[kind])
+		}
+		if kind == sourceCrypto {
+			return CryptoSource{}, nil
+		}
+		return nil, nil
+	case sourcePCG:
+		if _, ok := current.(*rand.PCG); !ok {
+			current = &rand.PCG{}
+		}
+		src = current
+	case sourceChaCha8:
+		if _, ok := current.(*rand.ChaCha8); !ok {
+			current = &rand.ChaCha8{}
+		}
+		src = current
+	case sourceCustom:
+		src = current
+	default:
+		return nil, fmt.Errorf("%w: unknown source kind %d", ErrInvalidState, kind)
+	}
+
+	if len(state) == 0 {
+		return src, nil
+	}
+
+	// Decode into a copy where we can, so a failure doesn't disturb the
+	// current source
+	src = cloneSource(src)
+	if u, ok := src.(encoding.BinaryUnmarshaler); ok {
+		if err := u.UnmarshalBinary(state); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidState, err)
+		}
+	}
+	return src, nil
+}
@@ -0,0 +1,155 @@
+package byteseq
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// An ExhaustionPolicy decides what a sequence does when a value is drawn
+// after every value has been handed out. It is chosen when the sequence is
+// created, with WithExhaustionPolicy.
+type ExhaustionPolicy int
+
+const (
+	// ExhaustError returns ErrExhausted, as sequences do by default.
+	ExhaustError ExhaustionPolicy = iota
+
+	// ExhaustCycle starts a new epoch, as described for WithCycle.
+	ExhaustCycle
+
+	// ExhaustBlock waits until a value is returned to the sequence by
+	// Release, Undo or Reset, and then draws it. Use NextValueCtx to give
+	// up waiting when a context is done. Other goroutines have to return
+	// the values, so the sequence is made safe for concurrent use as though
+	// WithLocking had been given. If every value is excluded nothing can
+	// ever be returned, and ErrExhausted is returned straight away.
+	//
+	// This turns the sequence into a bounded allocator: values are handed
+	// out at random, a caller who finds them all in use waits for one to be
+	// released, and nothing is ever handed out twice at the same time.
+	ExhaustBlock
+
+	// ExhaustZero returns the zero byte and no error, much like reading
+	// from /dev/zero once the real data has run out.
+	ExhaustZero
+)
+
+var policyNames = []string{"ExhaustError", "ExhaustCycle", "ExhaustBlock", "ExhaustZero"}
+
+func (p ExhaustionPolicy) String() string {
+	if p < 0 || int(p) >= len(policyNames) {
+		return fmt.Sprintf("ExhaustionPolicy(%d)", int(p))
+	}
+	return policyNames[p]
+}
+
+// WithExhaustionPolicy sets what the sequence does when a value is drawn
+// after it has run out. The policy applies to every way of drawing a value,
+// apart from Peek and TryNextValue, which always report that there is
+// nothing to draw rather than waiting or handing out a zero. The
+// constrained draws, such as NextInRange, apply the policy only when the
+// sequence as a whole has run out; while values are left but none of them
+// meet the constraint, they still return ErrRangeExhausted or ErrNoMatch.
+// Under ExhaustCycle, ExhaustBlock and ExhaustZero, loops that draw until an
+// error, such as Values and ForEach, don't end on their own.
+func WithExhaustionPolicy(policy ExhaustionPolicy) Option {
+	return func(c *config) {
+		if policy < ExhaustError || policy > ExhaustZero {
+			c.fail("unknown exhaustion policy %v", policy)
+			return
+		}
+		c.policy = policy
+		if policy == ExhaustBlock {
+			c.locking = true
+		}
+	}
+}
+
+// WithCycle makes the sequence start over when it runs out, rather than
+// returning ErrExhausted: once every value has been drawn, the next draw
+// starts a new epoch in which every value is available again, apart from
+// the exclusions, in a fresh random order. Within an epoch each value is
+// still drawn only once, so the values stay as spread out as possible for
+// as long as the sequence runs. Epoch reports how many times it has
+// started over. It is the same as WithExhaustionPolicy(ExhaustCycle).
+//
+// HasMore and Remaining describe the current epoch, so loops that stop when
+// HasMore reports false, such as Drain, still end with the epoch. A
+// sequence with every value excluded has nothing to cycle through and
+// still returns ErrExhausted.
+func WithCycle() Option {
+	return WithExhaustionPolicy(ExhaustCycle)
+}
+
+// Epoch returns how many times a sequence created with WithCycle has run
+// out and started over. It is zero until the first value of the second
+// epoch is drawn, and always zero for other sequences. Reset sets it back
+// to zero.
+func (r *RandomByteSeq) Epoch() int {
+	r.lock()
+	defer r.unlock()
+	return r.epoch
+}
+
+// available reports whether there is a value to draw, starting a new epoch
+// first if the sequence has run out and cycles.
+func (r *RandomByteSeq) available() bool {
+	r.reclaim()
+	if r.remainingValues > 0 {
+		return true
+	}
+	if r.policy != ExhaustCycle || r.excludedRemaining == 0 {
+		return false
+	}
+
+	r.consumedBytes = r.excludedBytes
+	r.remainingValues = r.excludedRemaining
+	r.rebuildPool()
+	r.reserved = ByteSet{}
+	r.resetLeases()
+	r.checkWatermarks()
+	r.epoch++
+	r.debug("epoch started", slog.Int("epoch", r.epoch))
+	return true
+}
+
+// awaitAvailable applies the exhaustion policy until there is a value to
+// draw, reporting zero if the policy says to hand out a zero instead. The
+// lock must be held, and is held again on return.
+func (r *RandomByteSeq) awaitAvailable(ctx context.Context) (zero bool, err error) {
+	for !r.available() {
+		switch {
+		case r.policy == ExhaustZero:
+			return true, nil
+		case r.policy == ExhaustBlock && r.excludedRemaining > 0:
+			if err := r.awaitValue(ctx); err != nil {
+				return false, err
+			}
+		default:
+			r.debug("sequence exhausted")
+			return false, ErrExhausted
+		}
+	}
+	return false, nil
+}
+
+// awaitValue gives up the lock until a value may have been returned to the
+// sequence, or ctx is done. The lock must be held, and is held again on
+// return.
+func (r *RandomByteSeq) awaitValue(ctx context.Context) error {
+	if r.wake == nil {
+		r.wake = make(chan struct{})
+	}
+	wake := r.wake
+
+	r.unlock()
+	defer r.lock()
+
+	select {
+	case <-wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
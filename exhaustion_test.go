@@ -0,0 +1,151 @@
+package byteseq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCycleStartsNewEpoch(t *testing.T) {
+	byteSeq := NewRangeSeq(0, 9, WithCycle())
+
+	for epoch := 0; epoch < 3; epoch++ {
+		var seen [256]bool
+		for i := 0; i < 10; i++ {
+			value, err := byteSeq.NextValue()
+			if err != nil {
+				t.Fatalf("Unexpected error from a cycling sequence: %v", err)
+			}
+			if value > 9 {
+				t.Errorf("Cycling brought back excluded value 0x%02x", value)
+			}
+			if seen[value] {
+				t.Errorf("Value 0x%02x repeated within epoch %d", value, epoch)
+			}
+			seen[value] = true
+		}
+		if got := byteSeq.Epoch(); got != epoch {
+			t.Errorf("Expected epoch %d, got %d", epoch, got)
+		}
+		if byteSeq.HasMore() {
+			t.Errorf("Expected HasMore to report the end of epoch %d", epoch)
+		}
+	}
+
+	byteSeq.Reset()
+	if got := byteSeq.Epoch(); got != 0 {
+		t.Errorf("Expected Reset to set the epoch back to zero, got %d", got)
+	}
+}
+
+func TestCycleWithEverythingExcluded(t *testing.T) {
+	byteSeq := NewRangeSeq(1, 0, WithCycle())
+	if _, err := byteSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted with nothing to cycle through, got %v", err)
+	}
+}
+
+func TestExhaustZeroPolicy(t *testing.T) {
+	byteSeq := NewRangeSeq(1, 2, WithExhaustionPolicy(ExhaustZero))
+	values, err := byteSeq.NextN(4)
+	if err != nil {
+		t.Fatalf("Expected no error under ExhaustZero, got %v", err)
+	}
+	if values[2] != 0 || values[3] != 0 {
+		t.Errorf("Expected zeros once the sequence ran out, got %v", values)
+	}
+	if _, ok := byteSeq.TryNextValue(); ok {
+		t.Errorf("Expected TryNextValue to report exhaustion rather than a zero")
+	}
+}
+
+func TestExhaustBlockPolicyWaitsForRelease(t *testing.T) {
+	byteSeq := NewRangeSeq(7, 7, WithExhaustionPolicy(ExhaustBlock))
+	first, _ := byteSeq.NextValue()
+
+	got := make(chan byte)
+	go func() {
+		value, err := byteSeq.NextValue()
+		if err != nil {
+			t.Errorf("Unexpected error from a blocked draw: %v", err)
+		}
+		got <- value
+	}()
+
+	select {
+	case value := <-got:
+		t.Fatalf("Expected the draw to wait, but it returned 0x%02x", value)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	byteSeq.Release(first)
+	select {
+	case value := <-got:
+		if value != first {
+			t.Errorf("Expected the released value 0x%02x, got 0x%02x", first, value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the blocked draw to get the released value")
+	}
+}
+
+func TestExhaustionPolicyOption(t *testing.T) {
+	if _, err := NewRandomSeqChecked(nil, WithExhaustionPolicy(ExhaustionPolicy(9))); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for an unknown policy, got %v", err)
+	}
+	if got := ExhaustBlock.String(); got != "ExhaustBlock" {
+		t.Errorf("Expected ExhaustBlock, got %q", got)
+	}
+
+	byteSeq := NewRangeSeq(1, 0, WithExhaustionPolicy(ExhaustBlock))
+	if _, err := byteSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted when nothing can ever be released, got %v", err)
+	}
+}
+
+func TestBlockedDrawHonoursContext(t *testing.T) {
+	byteSeq := NewRangeSeq(0, 1, WithExhaustionPolicy(ExhaustBlock))
+	drawn, _ := byteSeq.NextN(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := byteSeq.NextValueCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the blocked draw to give up with the context, got %v", err)
+	}
+
+	// A value released after the draw gave up stays available
+	byteSeq.Release(drawn[1])
+	value, err := byteSeq.NextValueCtx(context.Background())
+	if err != nil || value != drawn[1] {
+		t.Errorf("Expected the released value 0x%02x, got 0x%02x and %v", drawn[1], value, err)
+	}
+}
+
+func TestBlockingAllocatorUnderContention(t *testing.T) {
+	byteSeq := NewRangeSeq(0, 3, WithExhaustionPolicy(ExhaustBlock))
+
+	var inUse [4]atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				value, err := byteSeq.NextValue()
+				if err != nil {
+					t.Errorf("Unexpected error from the allocator: %v", err)
+					return
+				}
+				if inUse[value].Swap(true) {
+					t.Errorf("Value %d handed out while still in use", value)
+				}
+				inUse[value].Store(false)
+				byteSeq.Release(value)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,3 +1,5 @@
 module github.com/owenjklan/byteseq
 
-go 1.21
+go 1.23
+
+require golang.org/x/time v0.10.0
@@ -0,0 +1,75 @@
+package byteseq
+
+import "slices"
+
+// WithHistory makes the sequence record every value it draws, in the order
+// they were drawn, for auditing a run or reproducing a problem seen in one.
+// The record is read back with History. It covers every way of drawing a
+// value, including Stream, Read and the batch methods, but not values
+// consumed with Consume. A value that is released and then drawn again is
+// recorded each time it is drawn, so with Release in use the record can
+// grow beyond 256 values. It is cleared by Reset, and is not part of the
+// encoded state.
+func WithHistory() Option {
+	return func(c *config) {
+		c.history = true
+	}
+}
+
+// History returns the values drawn from the sequence so far, oldest first,
+// or nil if the sequence wasn't created with WithHistory.
+func (r *RandomByteSeq) History() []byte {
+	r.lock()
+	defer r.unlock()
+	return slices.Clone(r.history)
+}
+
+// record notes that a value has been drawn, adding it to the history if one
+// is being kept, starting its lease if leases are in use and telling any
+// OnConsume functions
+func (r *RandomByteSeq) record(b byte) {
+	if r.keepHistory {
+		r.history = append(r.history, b)
+	}
+	r.lease(b)
+	r.notifyConsumed(b)
+	r.debugValue("value drawn", b)
+}
+
+// IndexOf returns the position in the history at which b was drawn, counting
+// from zero, so "when was this value handed out" can be answered when
+// tracing a problem back to a draw. If b has been drawn more than once, the
+// latest position is returned. It reports false if b hasn't been drawn, or
+// the sequence wasn't created with WithHistory.
+func (r *RandomByteSeq) IndexOf(b byte) (int, bool) {
+	r.lock()
+	defer r.unlock()
+
+	for i := len(r.history) - 1; i >= 0; i-- {
+		if r.history[i] == b {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Undo returns the last n values drawn to the sequence, so they can be drawn
+// again, and takes them off the history. It returns how many values were
+// returned, which is fewer than n if the history is shorter, or if some of
+// the values have already been released. Undo needs the history, so it does
+// nothing unless the sequence was created with WithHistory.
+func (r *RandomByteSeq) Undo(n int) int {
+	r.lock()
+	defer r.unlock()
+
+	undone := 0
+	for ; n > 0 && len(r.history) > 0; n-- {
+		b := r.history[len(r.history)-1]
+		r.history = r.history[:len(r.history)-1]
+		if r.valueHasBeenConsumed(b) && !r.isExcluded(b) {
+			r.releaseByte(b)
+			undone++
+		}
+	}
+	return undone
+}
@@ -0,0 +1,111 @@
+package byteseq
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHistoryRecordsDrawOrder(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithHistory())
+
+	var drawn []byte
+	for i := 0; i < 5; i++ {
+		value, _ := byteSeq.NextValue()
+		drawn = append(drawn, value)
+	}
+	batch, _ := byteSeq.NextN(3)
+	drawn = append(drawn, batch...)
+
+	// Consumed values weren't drawn, so they aren't recorded
+	for _, b := range byteSeq.Unconsumed()[:2] {
+		_, _ = byteSeq.Consume(b)
+	}
+
+	if got := byteSeq.History(); !slices.Equal(got, drawn) {
+		t.Errorf("Expected history %v, got %v", drawn, got)
+	}
+
+	clone := byteSeq.Clone()
+	_, _ = clone.NextValue()
+	if got := byteSeq.History(); len(got) != 8 {
+		t.Errorf("Expected drawing from a clone to leave the original's history alone, got %d entries", len(got))
+	}
+
+	byteSeq.Reset()
+	if got := byteSeq.History(); len(got) != 0 {
+		t.Errorf("Expected Reset to clear the history, got %v", got)
+	}
+}
+
+func TestHistoryIsOptIn(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	_, _ = byteSeq.NextN(10)
+	if got := byteSeq.History(); got != nil {
+		t.Errorf("Expected no history without WithHistory, got %v", got)
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithHistory())
+	drawn, _ := byteSeq.NextN(10)
+
+	for i, b := range drawn {
+		if got, ok := byteSeq.IndexOf(b); !ok || got != i {
+			t.Errorf("Expected 0x%02x at position %d, got %d, %v", b, i, got, ok)
+		}
+	}
+
+	unconsumed := byteSeq.Unconsumed()[0]
+	if _, ok := byteSeq.IndexOf(unconsumed); ok {
+		t.Errorf("Expected undrawn value 0x%02x not to be found", unconsumed)
+	}
+
+	// A value drawn again is found at its latest position
+	byteSeq.Release(drawn[0])
+	for byteSeq.HasMore() {
+		if value, _ := byteSeq.NextValue(); value == drawn[0] {
+			break
+		}
+	}
+	if got, _ := byteSeq.IndexOf(drawn[0]); got < 10 {
+		t.Errorf("Expected the redrawn value's latest position, got %d", got)
+	}
+}
+
+func TestUndoReturnsRecentDraws(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithHistory())
+	drawn, _ := byteSeq.NextN(10)
+
+	if got := byteSeq.Undo(3); got != 3 {
+		t.Errorf("Expected 3 values to be undone, got %d", got)
+	}
+	if got := byteSeq.Remaining(); got != 249 {
+		t.Errorf("Expected 249 values after undoing 3 of 10 draws, got %d", got)
+	}
+	for _, b := range drawn[7:] {
+		if byteSeq.IsConsumed(b) {
+			t.Errorf("Expected undone value 0x%02x to be back in the sequence", b)
+		}
+	}
+	if got := byteSeq.History(); !slices.Equal(got, drawn[:7]) {
+		t.Errorf("Expected the undone draws to leave the history, got %v", got)
+	}
+
+	// A value released in the meantime isn't counted again
+	byteSeq.Release(drawn[6])
+	if got := byteSeq.Undo(100); got != 6 {
+		t.Errorf("Expected the remaining 6 consumed draws to be undone, got %d", got)
+	}
+	if got := byteSeq.Remaining(); got != 256 {
+		t.Errorf("Expected every value back after undoing everything, got %d", got)
+	}
+	checkPool(t, byteSeq)
+}
+
+func TestUndoNeedsHistory(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	_, _ = byteSeq.NextN(5)
+	if got := byteSeq.Undo(5); got != 0 {
+		t.Errorf("Expected Undo to do nothing without history, got %d", got)
+	}
+}
@@ -0,0 +1,130 @@
+package byteseq
+
+import "slices"
+
+// watermark is a threshold set with WithWatermark, with whether consumption
+// is currently at or past it.
+type watermark struct {
+	fraction float64
+	fn       func(remaining int)
+	reached  bool
+}
+
+// WithWatermark calls fn when consumption crosses fraction of the values
+// the sequence started with, for warning that it is running low before it
+// runs out. fn is given the number of values remaining at the time. It is
+// called once for each crossing: if values go back to the sequence, through
+// Release, Reset or a new cycle, and consumption drops back below fraction,
+// fn is called again the next time it is crossed. The option can be given
+// more than once to set several thresholds, such as 0.5 and 0.9.
+//
+// Consumption counts draws and Consume alike, and is measured against the
+// values left after the construction-time exclusions. fn is called with the
+// sequence's lock held, so it must not use the sequence. Clones and
+// snapshots keep the watermarks, but shards made by Split don't. fraction
+// must be greater than 0 and no more than 1.
+func WithWatermark(fraction float64, fn func(remaining int)) Option {
+	return func(c *config) {
+		if !(fraction > 0 && fraction <= 1) {
+			c.fail("watermark %v is not in (0, 1]", fraction)
+			return
+		}
+		if fn == nil {
+			c.fail("watermark func is nil")
+			return
+		}
+		c.watermarks = append(c.watermarks, watermark{fraction: fraction, fn: fn})
+	}
+}
+
+// OnConsume calls fn with each value the sequence draws, and the number of
+// values remaining after it, so logging, metrics or tracing can follow a
+// sequence without wrapping it. It sees every kind of draw, including
+// batches, iterators, constrained draws and the io.Reader methods, and is
+// given the value as drawn, before any transform. Values marked with
+// Consume aren't reported, as the caller already knows about them. The
+// option can be given more than once, and the functions are called in the
+// order given.
+//
+// fn is called with the sequence's lock held, so it must not use the
+// sequence. Clones and snapshots share the functions, but shards made by
+// Split don't get them.
+func OnConsume(fn func(value byte, remaining int)) Option {
+	return func(c *config) {
+		if fn == nil {
+			c.fail("consume func is nil")
+			return
+		}
+		c.onConsume = append(c.onConsume, fn)
+	}
+}
+
+// OnExhausted calls fn each time a draw takes the last value the sequence
+// has, so cleanup or epoch-rotation logic can run without every caller
+// checking HasMore after each draw. It is called once for each time the
+// sequence runs out: once at the end of each epoch under WithCycle, and
+// again if values are released and drawn until it runs out once more.
+// Running out through Consume doesn't count, as the caller already knows.
+// The option can be given more than once, and the functions are called in
+// the order given.
+//
+// Unlike the other hooks, fn is called after the sequence's lock has been
+// released, just before the call that drew the last value returns, so it
+// may use the sequence, for instance to Reset it. Clones and snapshots
+// share the functions, but shards made by Split don't get them.
+func OnExhausted(fn func()) Option {
+	return func(c *config) {
+		if fn == nil {
+			c.fail("exhausted func is nil")
+			return
+		}
+		c.onExhausted = append(c.onExhausted, fn)
+	}
+}
+
+// checkWatermarks calls the functions of any watermarks that consumption
+// has just reached, and rearms those it has dropped back below. It is
+// called whenever the number of remaining values changes.
+func (r *RandomByteSeq) checkWatermarks() {
+	if len(r.watermarks) == 0 || r.excludedRemaining == 0 {
+		return
+	}
+
+	consumed := float64(r.excludedRemaining-r.remainingValues) / float64(r.excludedRemaining)
+	for i := range r.watermarks {
+		w := &r.watermarks[i]
+		if consumed < w.fraction {
+			w.reached = false
+		} else if !w.reached {
+			w.reached = true
+			w.fn(r.remainingValues)
+		}
+	}
+}
+
+// notifyConsumed tells the OnConsume functions about a drawn value, and
+// notes whether it was the last one for the OnExhausted functions
+func (r *RandomByteSeq) notifyConsumed(b byte) {
+	for _, fn := range r.onConsume {
+		fn(b, r.remainingValues)
+	}
+	if r.remainingValues == 0 && len(r.onExhausted) > 0 {
+		r.exhaustedPending++
+	}
+}
+
+// notifyExhausted calls the OnExhausted functions once for each time the
+// sequence ran out. It is given them by unlock, as they are called without
+// the lock held.
+func notifyExhausted(fns []func(), times int) {
+	for ; times > 0; times-- {
+		for _, fn := range fns {
+			fn()
+		}
+	}
+}
+
+// cloneHooks gives a copy of the sequence its own hook state
+func (r *RandomByteSeq) cloneHooks() {
+	r.watermarks = slices.Clone(r.watermarks)
+}
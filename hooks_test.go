@@ -0,0 +1,155 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWatermarksFireOnceWhenCrossed(t *testing.T) {
+	var fired []int
+	record := func(remaining int) { fired = append(fired, remaining) }
+	byteSeq := NewRangeSeq(0, 9, WithWatermark(0.5, record), WithWatermark(0.9, record))
+
+	for i := 0; i < 4; i++ {
+		_, _ = byteSeq.NextValue()
+	}
+	if len(fired) != 0 {
+		t.Errorf("Expected no watermarks below 50%%, got %v", fired)
+	}
+
+	_, _ = byteSeq.NextValue()
+	_, _ = byteSeq.NextValue()
+	if len(fired) != 1 || fired[0] != 5 {
+		t.Errorf("Expected the 50%% watermark with 5 remaining, got %v", fired)
+	}
+
+	// Consume counts as consumption too
+	for _, b := range byteSeq.Unconsumed()[:3] {
+		_, _ = byteSeq.Consume(b)
+	}
+	if len(fired) != 2 || fired[1] != 1 {
+		t.Errorf("Expected the 90%% watermark with 1 remaining, got %v", fired)
+	}
+}
+
+func TestWatermarksRearm(t *testing.T) {
+	fired := 0
+	byteSeq := NewRangeSeq(0, 3, WithWatermark(0.5, func(int) { fired++ }))
+
+	values, _ := byteSeq.NextN(2)
+	byteSeq.Release(values[0])
+	_, _ = byteSeq.NextValue()
+	if fired != 2 {
+		t.Errorf("Expected the watermark to fire again after dropping below it, fired %d times", fired)
+	}
+
+	byteSeq.Reset()
+	_, _ = byteSeq.NextN(3)
+	if fired != 3 {
+		t.Errorf("Expected the watermark to rearm on Reset, fired %d times", fired)
+	}
+
+	// Clones keep their own record of what has fired
+	dup := byteSeq.Clone()
+	dup.Reset()
+	_, _ = dup.NextN(2)
+	_, _ = byteSeq.NextValue()
+	if fired != 4 {
+		t.Errorf("Expected only the clone's watermark to fire, fired %d times", fired)
+	}
+}
+
+func TestWatermarkOptionChecks(t *testing.T) {
+	for _, fraction := range []float64{0, -0.5, 1.5} {
+		if _, err := NewRandomSeqChecked(nil, WithWatermark(fraction, func(int) {})); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption for watermark %v, got %v", fraction, err)
+		}
+	}
+	if _, err := NewRandomSeqChecked(nil, WithWatermark(0.5, nil)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a nil func, got %v", err)
+	}
+}
+
+func TestOnConsumeSeesEveryDraw(t *testing.T) {
+	type event struct {
+		value     byte
+		remaining int
+	}
+	var events []event
+	byteSeq := NewRangeSeq(0x10, 0x17, WithTransform(func(b byte) byte { return ^b }), OnConsume(func(b byte, remaining int) {
+		events = append(events, event{b, remaining})
+	}))
+
+	first, _ := byteSeq.NextValue()
+	_, _ = byteSeq.NextN(2)
+	_, _ = byteSeq.NextInRange(0x10, 0x17)
+	_, _ = byteSeq.Consume(byteSeq.Unconsumed()[0])
+	rest := byteSeq.Drain()
+
+	if len(events) != 4+len(rest) {
+		t.Fatalf("Expected an event for each of the %d draws, got %d", 4+len(rest), len(events))
+	}
+	if events[0].value != ^first {
+		t.Errorf("Expected the value as drawn, 0x%02x, got 0x%02x", ^first, events[0].value)
+	}
+	for i, e := range events {
+		if i > 0 && e.remaining >= events[i-1].remaining {
+			t.Errorf("Expected the remaining count to fall, got %v", events)
+		}
+	}
+	if last := events[len(events)-1]; last.remaining != 0 {
+		t.Errorf("Expected the last draw to leave nothing, got %d", last.remaining)
+	}
+}
+
+func TestOnConsumeOptionChecks(t *testing.T) {
+	if _, err := NewRandomSeqChecked(nil, OnConsume(nil)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a nil func, got %v", err)
+	}
+}
+
+func TestOnExhaustedFiresOncePerRunOut(t *testing.T) {
+	fired := 0
+	byteSeq := NewRangeSeq(0, 2, WithLocking(), OnExhausted(func() { fired++ }))
+
+	_, _ = byteSeq.NextValue()
+	_, _ = byteSeq.NextValue()
+	if fired != 0 {
+		t.Errorf("Expected nothing before the last value, fired %d times", fired)
+	}
+	last, _ := byteSeq.NextValue()
+	_, _ = byteSeq.NextValue()
+	if fired != 1 {
+		t.Errorf("Expected one call for running out, fired %d times", fired)
+	}
+
+	byteSeq.Release(last)
+	_ = byteSeq.Drain()
+	if fired != 2 {
+		t.Errorf("Expected another call after running out again, fired %d times", fired)
+	}
+}
+
+func TestOnExhaustedCanUseTheSequence(t *testing.T) {
+	var byteSeq *RandomByteSeq
+	byteSeq = NewRangeSeq(0, 1, WithLocking(), OnExhausted(func() {
+		byteSeq.Reset()
+	}))
+
+	values, err := byteSeq.NextN(2)
+	if err != nil || len(values) != 2 {
+		t.Fatalf("Expected both values, got %v (%v)", values, err)
+	}
+	if byteSeq.Remaining() != 2 {
+		t.Errorf("Expected the hook to have reset the sequence, %d remaining", byteSeq.Remaining())
+	}
+}
+
+func TestOnExhaustedAtEndOfEachEpoch(t *testing.T) {
+	fired := 0
+	byteSeq := NewRangeSeq(0, 3, WithCycle(), OnExhausted(func() { fired++ }))
+	_, _ = byteSeq.NextN(10)
+	if fired != 2 {
+		t.Errorf("Expected a call at the end of each of 2 full epochs, fired %d times", fired)
+	}
+}
@@ -0,0 +1,233 @@
+// Package idalloc allocates IDs from 8-, 16- and 32-bit spaces, handing
+// each one out once until it is freed, in the manner of process IDs,
+// session IDs or transaction IDs.
+//
+// An Allocator hands out IDs either at random, which makes them hard to
+// guess and spreads reuse of freed IDs across the space, or lowest first,
+// for protocols that call for the smallest free ID. Random allocation is
+// built on the virtual shuffle from the uniq package, so an Allocator over
+// all of uint32 starts out small and only grows with the IDs in use.
+package idalloc
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/owenjklan/byteseq/internal/randutil"
+	"github.com/owenjklan/byteseq/uniq"
+)
+
+// ErrExhausted is returned by Allocate when every ID is in use.
+var ErrExhausted = errors.New("no free IDs")
+
+// ID is the set of types an Allocator can hand out.
+type ID interface {
+	~uint8 | ~uint16 | ~uint32
+}
+
+// A Source is the minimal random number generator an Allocator needs. It is
+// the same as rand.Source from math/rand/v2 and byteseq.Source.
+type Source = randutil.Source
+
+// Policy chooses which free ID an Allocator hands out next.
+type Policy int
+
+const (
+	// Random hands out a free ID chosen uniformly at random.
+	Random Policy = iota
+	// LowestFirst hands out the numerically lowest free ID.
+	LowestFirst
+)
+
+var policyNames = []string{"Random", "LowestFirst"}
+
+// String returns the policy's name, as used in its constant.
+func (p Policy) String() string {
+	if p < 0 || int(p) >= len(policyNames) {
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+	return policyNames[p]
+}
+
+// An Allocator hands out IDs of type T, each one only once until it is
+// freed. It is safe for concurrent use.
+type Allocator[T ID] struct {
+	mu     sync.Mutex
+	policy Policy
+	random *uniq.RandomSeq[T] // for Random
+	lowest *lowestFree        // for LowestFirst
+}
+
+// New returns an Allocator over every value of T, with nothing allocated,
+// handing IDs out according to policy. Random draws from the package-global
+// source. IDs that must never be handed out, such as zero, can be set aside
+// with Reserve. New panics if policy is not one of the Policy constants.
+func New[T ID](policy Policy) *Allocator[T] {
+	return NewFromSource[T](nil, policy)
+}
+
+// NewFromSource is like New, but Random draws from src instead of the
+// package-global source. A nil src selects the package-global source.
+func NewFromSource[T ID](src Source, policy Policy) *Allocator[T] {
+	a := &Allocator[T]{policy: policy}
+	switch policy {
+	case Random:
+		// The whole of a type of at most 32 bits is always a valid range
+		a.random, _ = uniq.NewFromSource(src, 0, ^T(0), nil)
+	case LowestFirst:
+		a.lowest = newLowestFree(uint64(^T(0)) + 1)
+	default:
+		panic("idalloc: unknown policy " + policy.String())
+	}
+	return a
+}
+
+// Policy returns the policy the Allocator was created with.
+func (a *Allocator[T]) Policy() Policy {
+	return a.policy
+}
+
+// Allocate hands out a free ID, which stays allocated until it is given back
+// with Free. It returns ErrExhausted if every ID is in use.
+func (a *Allocator[T]) Allocate() (T, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.lowest != nil {
+		id, ok := a.lowest.allocate()
+		if !ok {
+			return 0, ErrExhausted
+		}
+		return T(id), nil
+	}
+
+	id, err := a.random.NextValue()
+	if err != nil {
+		return 0, ErrExhausted
+	}
+	return id, nil
+}
+
+// Free gives id back so that it can be allocated again, reporting whether it
+// was allocated.
+func (a *Allocator[T]) Free(id T) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.lowest != nil {
+		return a.lowest.free(uint64(id))
+	}
+	return a.random.Release(id)
+}
+
+// Reserve allocates a particular id, for IDs with a fixed meaning or ones
+// already in use elsewhere, reporting false if it was already allocated. A
+// reserved ID is freed with Free like any other.
+func (a *Allocator[T]) Reserve(id T) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.lowest != nil {
+		return a.lowest.reserve(uint64(id))
+	}
+	return a.random.Consume(id)
+}
+
+// IsAllocated reports whether id is currently allocated.
+func (a *Allocator[T]) IsAllocated(id T) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.lowest != nil {
+		return a.lowest.isAllocated(uint64(id))
+	}
+	return a.random.IsConsumed(id)
+}
+
+// Available returns how many IDs are free.
+func (a *Allocator[T]) Available() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.lowest != nil {
+		return a.lowest.size - uint64(len(a.lowest.allocated))
+	}
+	return a.random.Remaining()
+}
+
+// lowestFree tracks allocations for LowestFirst. IDs from next upwards have
+// never been handed out unless they are in allocated; freed IDs below next
+// wait in a heap. The heap may hold entries for IDs that have since been
+// reserved again, which are skipped when they reach the top.
+type lowestFree struct {
+	size      uint64
+	next      uint64
+	freed     uint64Heap
+	allocated map[uint64]struct{}
+}
+
+func newLowestFree(size uint64) *lowestFree {
+	return &lowestFree{size: size, allocated: make(map[uint64]struct{})}
+}
+
+func (l *lowestFree) allocate() (uint64, bool) {
+	for l.freed.Len() > 0 {
+		id := heap.Pop(&l.freed).(uint64)
+		if !l.isAllocated(id) {
+			l.allocated[id] = struct{}{}
+			return id, true
+		}
+	}
+
+	for l.next < l.size && l.isAllocated(l.next) {
+		l.next++
+	}
+	if l.next == l.size {
+		return 0, false
+	}
+	id := l.next
+	l.next++
+	l.allocated[id] = struct{}{}
+	return id, true
+}
+
+func (l *lowestFree) free(id uint64) bool {
+	if !l.isAllocated(id) {
+		return false
+	}
+	delete(l.allocated, id)
+	if id < l.next {
+		heap.Push(&l.freed, id)
+	}
+	return true
+}
+
+func (l *lowestFree) reserve(id uint64) bool {
+	if l.isAllocated(id) {
+		return false
+	}
+	l.allocated[id] = struct{}{}
+	return true
+}
+
+func (l *lowestFree) isAllocated(id uint64) bool {
+	_, ok := l.allocated[id]
+	return ok
+}
+
+// uint64Heap is a min-heap of IDs for container/heap.
+type uint64Heap []uint64
+
+func (h uint64Heap) Len() int           { return len(h) }
+func (h uint64Heap) Less(i, j int) bool { return h[i] < h[j] }
+func (h uint64Heap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *uint64Heap) Push(x any)        { *h = append(*h, x.(uint64)) }
+
+func (h *uint64Heap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,113 @@
+package idalloc
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRandomAllocatorCoversSpace(t *testing.T) {
+	alloc := NewFromSource[uint8](rand.NewPCG(1, 2), Random)
+	if !alloc.Reserve(0) || alloc.Reserve(0) {
+		t.Errorf("Expected only the first Reserve of 0 to succeed")
+	}
+
+	seen := map[uint8]bool{}
+	for alloc.Available() > 0 {
+		id, err := alloc.Allocate()
+		if err != nil || seen[id] || id == 0 {
+			t.Fatalf("Unexpected ID %d (%v)", id, err)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 255 {
+		t.Errorf("Expected 255 IDs, got %d", len(seen))
+	}
+	if _, err := alloc.Allocate(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+
+	if !alloc.Free(42) || alloc.Free(42) {
+		t.Errorf("Expected only the first Free of 42 to succeed")
+	}
+	if id, _ := alloc.Allocate(); id != 42 {
+		t.Errorf("Expected the freed ID 42 back, got %d", id)
+	}
+}
+
+func TestLowestFirstAllocator(t *testing.T) {
+	alloc := New[uint32](LowestFirst)
+	alloc.Reserve(0)
+	alloc.Reserve(3)
+
+	expectNext := func(expected uint32) {
+		t.Helper()
+		if id, err := alloc.Allocate(); err != nil || id != expected {
+			t.Errorf("Expected ID %d, got %d (%v)", expected, id, err)
+		}
+	}
+	expectNext(1)
+	expectNext(2)
+	expectNext(4)
+
+	alloc.Free(2)
+	alloc.Free(1)
+	alloc.Free(3)
+	alloc.Reserve(2)
+	expectNext(1)
+	expectNext(3)
+	expectNext(5)
+
+	if !alloc.IsAllocated(2) || alloc.IsAllocated(6) {
+		t.Errorf("Expected 2 to be allocated and 6 to be free")
+	}
+	if available := alloc.Available(); available != 1<<32-6 {
+		t.Errorf("Expected %d IDs available, got %d", uint64(1<<32-6), available)
+	}
+}
+
+func TestLowestFirstExhausts(t *testing.T) {
+	alloc := New[uint8](LowestFirst)
+	for i := 0; i < 256; i++ {
+		if id, err := alloc.Allocate(); err != nil || int(id) != i {
+			t.Fatalf("Expected ID %d, got %d (%v)", i, id, err)
+		}
+	}
+	if _, err := alloc.Allocate(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+	alloc.Free(200)
+	if id, _ := alloc.Allocate(); id != 200 {
+		t.Errorf("Expected the freed ID 200, got %d", id)
+	}
+}
+
+func TestRandomAllocator32Bit(t *testing.T) {
+	alloc := New[uint32](Random)
+	seen := map[uint32]bool{}
+	for i := 0; i < 1000; i++ {
+		id, _ := alloc.Allocate()
+		if seen[id] {
+			t.Fatalf("ID %d allocated twice", id)
+		}
+		seen[id] = true
+	}
+	if alloc.Available() != 1<<32-1000 {
+		t.Errorf("Expected %d IDs available, got %d", uint64(1<<32-1000), alloc.Available())
+	}
+}
+
+func TestUnknownPolicyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected an unknown policy to panic")
+		}
+	}()
+	New[uint16](Policy(7))
+}
+
+func TestPolicyString(t *testing.T) {
+	if LowestFirst.String() != "LowestFirst" || Policy(7).String() != "Policy(7)" {
+		t.Errorf("Unexpected policy names %q and %q", LowestFirst, Policy(7))
+	}
+}
@@ -0,0 +1,59 @@
+package idgen
+
+import (
+	"sync"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func TestIDsAreUniqueAcrossGoroutines(t *testing.T) {
+	gen, err := New(testKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	const perWorker = 1000
+	ids := make(chan uint64, 8*perWorker)
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ids <- gen.Next()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[uint64]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("ID %d handed out twice", id)
+		}
+		seen[id] = true
+	}
+	if gen.Position() != 8*perWorker {
+		t.Errorf("Expected position %d but got %d", 8*perWorker, gen.Position())
+	}
+}
+
+func TestResumeAndSequence(t *testing.T) {
+	gen, _ := New(testKey)
+	for i := 0; i < 50; i++ {
+		gen.Next()
+	}
+
+	resumed, _ := NewAt(testKey, gen.Position())
+	for i := 0; i < 10; i++ {
+		a, b := gen.Next(), resumed.Next()
+		if a != b {
+			t.Fatalf("Expected resumed generator to return %d but got %d", a, b)
+		}
+		if seq := gen.Sequence(a); seq != uint64(50+i) {
+			t.Errorf("Expected ID %d at position %d but got %d", a, 50+i, seq)
+		}
+	}
+}
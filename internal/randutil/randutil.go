@@ -0,0 +1,32 @@
+// Package randutil holds the random number helpers shared by byteseq and
+// its subpackages.
+package randutil
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// A Source is anything that produces uniformly distributed 64-bit values,
+// the same as rand.Source from math/rand/v2.
+type Source interface {
+	Uint64() uint64
+}
+
+// Uint64n returns a uniformly distributed number in [0, n) from src, using
+// Lemire's multiply-and-reject method so there's no modulo bias. A nil src
+// selects the package-global math/rand/v2 source. n must not be zero.
+func Uint64n(src Source, n uint64) uint64 {
+	if src == nil {
+		return rand.Uint64N(n)
+	}
+
+	hi, lo := bits.Mul64(src.Uint64(), n)
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			hi, lo = bits.Mul64(src.Uint64(), n)
+		}
+	}
+	return hi
+}
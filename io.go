@@ -0,0 +1,77 @@
+package byteseq
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Check the sequence keeps satisfying the io interfaces it's meant to
+var (
+	_ io.Reader     = (*RandomByteSeq)(nil)
+	_ io.ByteReader = (*RandomByteSeq)(nil)
+	_ io.WriterTo   = (*RandomByteSeq)(nil)
+)
+
+// Read implements io.Reader, filling p with unique values from the sequence.
+// Once the sequence is exhausted, Read returns io.EOF. A read that empties
+// the sequence part way through p returns the values it got with a nil
+// error, and the following read returns io.EOF. Any other error is returned
+// as it is.
+func (r *RandomByteSeq) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n, err := r.Fill(p)
+	switch {
+	case !errors.Is(err, ErrExhausted):
+		return n, err
+	case n == 0:
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// ReadByte implements io.ByteReader. It is NextValue with exhaustion
+// reported as io.EOF, so the sequence can be handed to anything that reads
+// from an io.ByteReader. Any other error, such as one from middleware, is
+// returned as it is.
+func (r *RandomByteSeq) ReadByte() (byte, error) {
+	value, err := r.NextValue()
+	switch {
+	case errors.Is(err, ErrExhausted):
+		return 0, io.EOF
+	case err != nil:
+		return 0, err
+	}
+	return value, nil
+}
+
+// WriteTo implements io.WriterTo, writing every remaining value to w in
+// random order with a single Write call. Values that w doesn't accept are
+// released back to the sequence, so nothing is lost if the write fails.
+func (r *RandomByteSeq) WriteTo(w io.Writer) (int64, error) {
+	// Keep the values as drawn, so the unwritten ones can be released even
+	// if they were transformed on the way out
+	var drawn, buf [256]byte
+	_ = r.waitN(context.Background(), r.Remaining())
+	r.lock()
+	resume := r.pauseReclaim()
+	n := r.remainingValues
+	for i := range n {
+		drawn[i], _ = r.draw()
+		buf[i], _ = r.output(drawn[i], nil)
+	}
+	resume()
+	r.unlock()
+
+	written, err := w.Write(buf[:n])
+	for _, value := range drawn[written:n] {
+		r.Release(value)
+	}
+	if err == nil && written < n {
+		err = io.ErrShortWrite
+	}
+	return int64(written), err
+}
@@ -0,0 +1,95 @@
+package byteseq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReaderCopiesAllValues(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0xAA})
+	var buf bytes.Buffer
+
+	n, err := io.Copy(&buf, byteSeq)
+	if err != nil || n != 255 {
+		t.Fatalf("Expected to copy 255 bytes but got %d (%v)", n, err)
+	}
+
+	var returnedValueCounts [256]int
+	for _, value := range buf.Bytes() {
+		returnedValueCounts[value]++
+	}
+	for value, count := range returnedValueCounts {
+		expected := 1
+		if value == 0xAA {
+			expected = 0
+		}
+		if count != expected {
+			t.Errorf("Expected %d occurrences of 0x%02x but got %d", expected, value, count)
+		}
+	}
+
+	if _, err := byteSeq.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected io.EOF from an exhausted sequence but got %v", err)
+	}
+}
+
+func TestReadByteReportsEOF(t *testing.T) {
+	var consumedBytes []byte
+	for i := 1; i < 256; i++ {
+		consumedBytes = append(consumedBytes, byte(i))
+	}
+	var reader io.ByteReader = NewRandomSeq(consumedBytes)
+
+	value, err := reader.ReadByte()
+	if value != 0x00 || err != nil {
+		t.Errorf("Expected 0x00 and no error but got 0x%02x (%v)", value, err)
+	}
+	if _, err := reader.ReadByte(); err != io.EOF {
+		t.Errorf("Expected io.EOF from an exhausted sequence but got %v", err)
+	}
+}
+
+func TestReadBytePassesOtherErrorsThrough(t *testing.T) {
+	errRefused := errors.New("refused")
+	byteSeq := NewRandomSeq(nil, WithMiddleware(func(DrawFunc) DrawFunc {
+		return func(context.Context) (byte, error) { return 0, errRefused }
+	}))
+
+	if _, err := byteSeq.ReadByte(); !errors.Is(err, errRefused) {
+		t.Errorf("Expected the middleware's error but got %v", err)
+	}
+}
+
+// limitedWriter accepts a fixed number of bytes, then fails
+type limitedWriter struct {
+	limit int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.limit {
+		return l.limit, io.ErrShortWrite
+	}
+	return len(p), nil
+}
+
+func TestWriteToReleasesUnwrittenValues(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	var buf bytes.Buffer
+
+	n, err := byteSeq.WriteTo(&buf)
+	if n != 256 || err != nil || byteSeq.HasMore() {
+		t.Fatalf("Expected all 256 values written but got %d (%v)", n, err)
+	}
+
+	byteSeq.Reset()
+	n, err = byteSeq.WriteTo(&limitedWriter{limit: 100})
+	if n != 100 || err == nil {
+		t.Errorf("Expected a failed write of 100 values but got %d (%v)", n, err)
+	}
+	if byteSeq.Remaining() != 156 {
+		t.Errorf("Expected 156 unwritten values released but %d remain", byteSeq.Remaining())
+	}
+}
@@ -0,0 +1,113 @@
+// Package ipv4perm emits every address in an IPv4 CIDR block exactly once,
+// in a pseudo-random order.
+//
+// It is the big brother of byteseq.RandomByteSeq for network sweeps. Rather
+// than tracking which addresses have been handed out, it walks a counter
+// through a keyed format-preserving permutation of the block, so a /8 costs
+// no more memory than a /24. The same key always gives the same order,
+// which means a sweep can be resumed from nothing more than the key and
+// its position.
+package ipv4perm
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/netip"
+
+	"github.com/owenjklan/byteseq/perm"
+)
+
+// ErrExhausted is returned once every address in the block has been emitted.
+var ErrExhausted = perm.ErrExhausted
+
+// ErrNotIPv4 is returned when the prefix given to New isn't a valid IPv4
+// one.
+var ErrNotIPv4 = errors.New("prefix is not IPv4")
+
+// KeySize is the size of the keys used by New, in bytes.
+const KeySize = perm.KeySize
+
+// A Seq emits the addresses of a CIDR block in pseudo-random order. It is
+// not safe for concurrent use.
+type Seq struct {
+	base uint32
+	perm *perm.Permutation
+	seq  *perm.Seq
+}
+
+// New returns a Seq over every address in prefix, including the network and
+// broadcast addresses. key must be KeySize bytes long; if it is nil, a
+// random key is chosen, giving a different order each time.
+func New(prefix netip.Prefix, key []byte) (*Seq, error) {
+	if !prefix.IsValid() || !prefix.Addr().Is4() {
+		return nil, ErrNotIPv4
+	}
+
+	if key == nil {
+		key = make([]byte, KeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := perm.NewBits(uint(32-prefix.Bits()), key)
+	if err != nil {
+		return nil, err
+	}
+
+	base := prefix.Masked().Addr().As4()
+	return &Seq{
+		base: uint32(base[0])<<24 | uint32(base[1])<<16 | uint32(base[2])<<8 | uint32(base[3]),
+		perm: p,
+		seq:  p.Seq(),
+	}, nil
+}
+
+// Size returns the number of addresses in the block.
+func (s *Seq) Size() uint64 {
+	return s.perm.Size()
+}
+
+// HasMore reports whether any addresses are left to emit.
+func (s *Seq) HasMore() bool {
+	return s.seq.HasMore()
+}
+
+// Remaining returns how many addresses are left to emit.
+func (s *Seq) Remaining() uint64 {
+	return s.seq.Remaining()
+}
+
+// Position returns how many addresses have been emitted so far. Together
+// with the key, it is all that's needed to resume a sweep with Seek.
+func (s *Seq) Position() uint64 {
+	return s.seq.Position()
+}
+
+// Seek moves the sequence to position, so the next address emitted is the
+// one that would have followed that many earlier addresses. Positions past
+// the end leave the sequence exhausted.
+func (s *Seq) Seek(position uint64) {
+	s.seq.Seek(position)
+}
+
+// NextValue returns the next address in the block's permuted order, or
+// ErrExhausted once every address has been emitted.
+func (s *Seq) NextValue() (netip.Addr, error) {
+	host, err := s.seq.NextValue()
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return s.addr(host), nil
+}
+
+// At returns the address at index i of the permuted order, without moving
+// the sequence. i must be less than Size.
+func (s *Seq) At(i uint64) netip.Addr {
+	return s.addr(s.perm.At(i))
+}
+
+func (s *Seq) addr(host uint64) netip.Addr {
+	v := s.base | uint32(host)
+	return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+}
@@ -0,0 +1,68 @@
+package ipv4perm
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestEveryAddressOnce(t *testing.T) {
+	for _, cidr := range []string{"192.168.1.0/24", "10.0.0.0/21", "172.16.5.4/31", "8.8.8.8/32"} {
+		prefix := netip.MustParsePrefix(cidr)
+		seq, err := New(prefix, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", cidr, err)
+		}
+
+		seen := map[netip.Addr]bool{}
+		for seq.HasMore() {
+			addr, _ := seq.NextValue()
+			if !prefix.Contains(addr) {
+				t.Errorf("%s: address %s is outside the block", cidr, addr)
+			}
+			if seen[addr] {
+				t.Errorf("%s: address %s emitted twice", cidr, addr)
+			}
+			seen[addr] = true
+		}
+
+		if uint64(len(seen)) != seq.Size() {
+			t.Errorf("%s: expected %d addresses but got %d", cidr, seq.Size(), len(seen))
+		}
+		if _, err := seq.NextValue(); !errors.Is(err, ErrExhausted) {
+			t.Errorf("%s: expected ErrExhausted but got %v", cidr, err)
+		}
+	}
+}
+
+func TestResumeWithSameKey(t *testing.T) {
+	prefix := netip.MustParsePrefix("10.1.0.0/16")
+	key := []byte("0123456789abcdef")
+
+	original, _ := New(prefix, key)
+	for i := 0; i < 1000; i++ {
+		_, _ = original.NextValue()
+	}
+
+	resumed, _ := New(prefix, key)
+	resumed.Seek(original.Position())
+	for i := 0; i < 100; i++ {
+		a, _ := original.NextValue()
+		b, _ := resumed.NextValue()
+		if a != b {
+			t.Fatalf("Expected resumed sweep to emit %s but got %s", a, b)
+		}
+	}
+}
+
+func TestRejectsNonIPv4Prefixes(t *testing.T) {
+	for name, prefix := range map[string]netip.Prefix{
+		"IPv6":         netip.MustParsePrefix("2001:db8::/64"),
+		"invalid bits": netip.PrefixFrom(netip.MustParseAddr("10.0.0.0"), 40),
+		"zero value":   {},
+	} {
+		if _, err := New(prefix, nil); !errors.Is(err, ErrNotIPv4) {
+			t.Errorf("%s: expected ErrNotIPv4 but got %v", name, err)
+		}
+	}
+}
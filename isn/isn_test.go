@@ -0,0 +1,67 @@
+package isn
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+var (
+	testKey = bytes.Repeat([]byte{0x5a}, KeySize)
+	client  = netip.MustParseAddrPort("192.0.2.1:49152")
+	server  = netip.MustParseAddrPort("[2001:db8::1]:443")
+)
+
+func TestNumbersDontRepeatForATuple(t *testing.T) {
+	g, err := New(testKey)
+	if err != nil {
+		t.Fatalf("Unexpected error creating generator: %v", err)
+	}
+
+	seen := make(map[uint32]bool)
+	for i := 0; i < 100000; i++ {
+		n := g.Next(client, server)
+		if seen[n] {
+			t.Fatalf("Number %d repeated after %d calls", n, i)
+		}
+		seen[n] = true
+	}
+}
+
+func TestTuplesGetDifferentNumbers(t *testing.T) {
+	a, _ := New(testKey)
+	b, _ := New(testKey)
+
+	other := netip.MustParseAddrPort("192.0.2.1:49153")
+	if a.Next(client, server) == b.Next(other, server) {
+		t.Errorf("Expected different tuples to get different numbers")
+	}
+	if a.Next(client, server) == b.Next(server, client) {
+		t.Errorf("Expected the direction of a tuple to matter")
+	}
+}
+
+func TestNewAtResumes(t *testing.T) {
+	g, _ := New(testKey)
+	for i := 0; i < 10; i++ {
+		g.Next(client, server)
+	}
+
+	resumed, _ := NewAt(testKey, g.Position())
+	if g.Next(client, server) != resumed.Next(client, server) {
+		t.Errorf("Expected a resumed generator to carry on where the first left off")
+	}
+
+	otherKey, _ := NewAt(bytes.Repeat([]byte{0xa5}, KeySize), resumed.Position()-1)
+	resumed, _ = NewAt(testKey, resumed.Position()-1)
+	if otherKey.Next(client, server) == resumed.Next(client, server) {
+		t.Errorf("Expected different keys to give different numbers")
+	}
+}
+
+func TestInvalidKey(t *testing.T) {
+	if _, err := New([]byte("short")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+}
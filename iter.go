@@ -0,0 +1,155 @@
+package byteseq
+
+import (
+	"context"
+	"iter"
+	"math/rand/v2"
+	"time"
+)
+
+// Values returns an iterator that draws values from the sequence until it is
+// exhausted, so the common "drain everything" loop becomes
+//
+//	for b := range seq.Values() {
+//		...
+//	}
+//
+// Each value is consumed as it is yielded. Breaking out of the loop early
+// leaves the remaining values in the sequence for later draws.
+func (r *RandomByteSeq) Values() iter.Seq[byte] {
+	return func(yield func(byte) bool) {
+		for {
+			value, err := r.NextValue()
+			if err != nil {
+				return
+			}
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// ForEach draws values from the sequence and calls fn with each one, until
+// fn returns false or the sequence is exhausted. It is the callback form of
+// Values. The lock is not held while fn runs, so fn may use the sequence
+// itself, for example to Release a value it can't use.
+func (r *RandomByteSeq) ForEach(fn func(byte) bool) {
+	for {
+		value, err := r.NextValue()
+		if err != nil || !fn(value) {
+			return
+		}
+	}
+}
+
+// Pull returns the sequence as a pull-style iterator, in the same form as
+// iter.Pull(seq.Values()) but without the goroutine switching that iter.Pull
+// needs. Each call to next draws and consumes a value, reporting false once
+// the sequence is exhausted or stop has been called. Calling stop abandons
+// the iterator, leaving any remaining values in the sequence; it is safe to
+// call more than once.
+func (r *RandomByteSeq) Pull() (next func() (byte, bool), stop func()) {
+	stopped := false
+
+	next = func() (byte, bool) {
+		if stopped {
+			return 0, false
+		}
+		value, err := r.NextValue()
+		if err != nil {
+			stopped = true
+			return 0, false
+		}
+		return value, true
+	}
+	stop = func() {
+		stopped = true
+	}
+	return next, stop
+}
+
+// Stream starts a goroutine that draws the remaining values and sends them on
+// the returned channel, which has a buffer of buf values. The channel is
+// closed once the sequence is exhausted or ctx is cancelled. A value that
+// was drawn but could not be sent before cancellation is released back to
+// the sequence; values already sitting in the channel's buffer stay consumed.
+//
+// The goroutine draws from the sequence while the caller carries on, so
+// unless the sequence was made with NewSafeRandomSeq, it must not be used by
+// anything else until the channel is closed.
+func (r *RandomByteSeq) Stream(ctx context.Context, buf int) <-chan byte {
+	ch := make(chan byte, buf)
+
+	go func() {
+		defer close(ch)
+		for ctx.Err() == nil {
+			value, err := r.nextRaw(ctx)
+			if err != nil {
+				return
+			}
+
+			out, _ := r.output(value, nil)
+			select {
+			case ch <- out:
+			case <-ctx.Done():
+				r.Release(value)
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// EmitEvery starts a goroutine that sends one value from the sequence on the
+// returned channel each interval, to spread work out over time. If jitter is
+// positive, each wait is lengthened by a random amount of up to jitter, so
+// that many emitters started together drift apart. The first value is sent
+// after the first wait. The channel is unbuffered, and is closed once the
+// sequence is exhausted or ctx is cancelled; as with Stream, a value that
+// was drawn but could not be sent is released back to the sequence, and the
+// sequence must not be used by anything else until the channel is closed
+// unless it was made with NewSafeRandomSeq.
+//
+// A slow receiver delays the following values rather than piling them up,
+// much like a time.Ticker dropping ticks.
+func (r *RandomByteSeq) EmitEvery(ctx context.Context, interval, jitter time.Duration) <-chan byte {
+	ch := make(chan byte)
+
+	go func() {
+		defer close(ch)
+
+		timer := time.NewTimer(jittered(interval, jitter))
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return
+			}
+
+			value, err := r.nextRaw(ctx)
+			if err != nil {
+				return
+			}
+			out, _ := r.output(value, nil)
+			select {
+			case ch <- out:
+			case <-ctx.Done():
+				r.Release(value)
+				return
+			}
+			timer.Reset(jittered(interval, jitter))
+		}
+	}()
+	return ch
+}
+
+// jittered returns interval lengthened by a random amount of up to jitter
+func jittered(interval, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + rand.N(jitter)
+}
@@ -0,0 +1,174 @@
+package byteseq
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestValuesDrainsSequence(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00, 0x01})
+	var returnedValueCounts [256]int
+
+	for value := range byteSeq.Values() {
+		returnedValueCounts[value]++
+	}
+
+	for value, count := range returnedValueCounts {
+		expected := 1
+		if value < 2 {
+			expected = 0
+		}
+		if count != expected {
+			t.Errorf("Expected %d occurrences of 0x%02x but got %d", expected, value, count)
+		}
+	}
+}
+
+func TestValuesStopsEarly(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+
+	drawn := 0
+	for range byteSeq.Values() {
+		drawn++
+		if drawn == 10 {
+			break
+		}
+	}
+
+	if byteSeq.Remaining() != 246 {
+		t.Errorf("Expected 246 values remaining after break but got %d", byteSeq.Remaining())
+	}
+}
+
+func TestForEachStopsWhenAsked(t *testing.T) {
+	byteSeq := NewSafeRandomSeq(nil)
+
+	calls := 0
+	byteSeq.ForEach(func(b byte) bool {
+		calls++
+		if calls == 5 {
+			// The sequence can be used from inside the callback
+			byteSeq.Release(b)
+			return false
+		}
+		return true
+	})
+
+	if calls != 5 {
+		t.Errorf("Expected 5 calls before stopping, got %d", calls)
+	}
+	if got := byteSeq.Remaining(); got != 252 {
+		t.Errorf("Expected 252 values left after drawing 5 and releasing 1, got %d", got)
+	}
+
+	calls = 0
+	byteSeq.ForEach(func(byte) bool {
+		calls++
+		return true
+	})
+	if calls != 252 || byteSeq.HasMore() {
+		t.Errorf("Expected ForEach to run through the remaining 252 values, got %d", calls)
+	}
+}
+
+func TestPullInterleavesAndStops(t *testing.T) {
+	first := NewRandomSeq(nil)
+	second := NewRandomSeq(nil)
+	nextFirst, stopFirst := first.Pull()
+	nextSecond, stopSecond := second.Pull()
+	defer stopSecond()
+
+	for i := 0; i < 5; i++ {
+		if _, ok := nextFirst(); !ok {
+			t.Fatalf("Expected a value from the first iterator")
+		}
+		if _, ok := nextSecond(); !ok {
+			t.Fatalf("Expected a value from the second iterator")
+		}
+	}
+
+	stopFirst()
+	if _, ok := nextFirst(); ok {
+		t.Errorf("Expected no values after stop")
+	}
+	if first.Remaining() != 251 || second.Remaining() != 251 {
+		t.Errorf("Expected 251 values left in each, got %d and %d", first.Remaining(), second.Remaining())
+	}
+}
+
+func TestStreamEmitsAllValues(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00})
+	var returnedValueCounts [256]int
+
+	for value := range byteSeq.Stream(context.Background(), 8) {
+		returnedValueCounts[value]++
+	}
+
+	for value, count := range returnedValueCounts {
+		expected := 1
+		if value == 0x00 {
+			expected = 0
+		}
+		if count != expected {
+			t.Errorf("Expected %d occurrences of 0x%02x but got %d", expected, value, count)
+		}
+	}
+}
+
+func TestStreamStopsOnCancel(t *testing.T) {
+	byteSeq := NewSafeRandomSeq(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := byteSeq.Stream(ctx, 0)
+	<-ch
+	<-ch
+	cancel()
+
+	// Drain until the producer notices and closes the channel
+	received := 2
+	for range ch {
+		received++
+	}
+
+	if byteSeq.Remaining() != 256-received {
+		t.Errorf("Expected %d values left after cancel but got %d", 256-received, byteSeq.Remaining())
+	}
+}
+
+func TestEmitEverySpacesValues(t *testing.T) {
+	byteSeq := NewRangeSeq(0, 4)
+
+	start := time.Now()
+	var values []byte
+	for value := range byteSeq.EmitEvery(context.Background(), 5*time.Millisecond, 5*time.Millisecond) {
+		values = append(values, value)
+	}
+
+	if len(values) != 5 {
+		t.Errorf("Expected all 5 values to be emitted, got %d", len(values))
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("Expected 5 values at 5ms intervals to take at least 25ms, took %v", elapsed)
+	}
+}
+
+func TestEmitEveryStopsOnCancel(t *testing.T) {
+	byteSeq := NewSafeRandomSeq(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := byteSeq.EmitEvery(ctx, time.Millisecond, 0)
+	<-ch
+	<-ch
+	cancel()
+
+	// A value may still slip through as the emitter notices the cancel
+	received := 2
+	for range ch {
+		received++
+	}
+
+	if got := byteSeq.Remaining(); got != 256-received {
+		t.Errorf("Expected only the %d received values to be consumed, but %d remain", received, got)
+	}
+}
@@ -0,0 +1,172 @@
+package byteseq
+
+import "time"
+
+// A Clock tells the time for sequences created with WithLeases. It is there
+// so tests can control the passing of time; the default uses time.Now.
+type Clock interface {
+	Now() time.Time
+}
+
+// leaseState tracks when each drawn value's lease runs out. A zero expiry
+// means the value has no lease.
+type leaseState struct {
+	ttl     time.Duration
+	clock   Clock
+	expiry  [256]time.Time
+	nextDue time.Time // earliest expiry, or zero if no lease is held
+	paused  bool      // set while a batch is being drawn, see pauseReclaim
+}
+
+// WithLeases makes every value the sequence draws a lease that runs out
+// after ttl, DHCP style. A value whose lease runs out without being renewed
+// with Renew goes back to the sequence, as though it had been released, so
+// values that a caller leaks are reclaimed rather than used up for good.
+// Releasing a value ends its lease early. Values taken with Consume, or
+// excluded at construction, aren't leased.
+//
+// Leases are reclaimed by the sequence itself whenever it is used, rather
+// than by a background goroutine, so a draw waiting under ExhaustBlock is
+// only woken by a lease running out if something else uses the sequence.
+// ttl must be positive.
+func WithLeases(ttl time.Duration) Option {
+	return func(c *config) {
+		if ttl <= 0 {
+			c.fail("lease ttl %v is not positive", ttl)
+			return
+		}
+		c.leaseTTL = ttl
+	}
+}
+
+// WithClock sets the clock used to time leases, so tests can move time on
+// by hand. A nil clock selects the real time.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// Renew extends the lease on b, which must be a value the sequence has
+// drawn, to ttl from now. It reports false if b doesn't hold a lease, either
+// because it was never drawn, was released, or its lease has already run
+// out, in which case the caller should stop using it.
+func (r *RandomByteSeq) Renew(b byte) bool {
+	r.lock()
+	defer r.unlock()
+
+	if r.leases == nil {
+		return false
+	}
+	r.reclaim()
+	if r.leases.expiry[b].IsZero() {
+		return false
+	}
+	r.leases.expiry[b] = r.leases.now().Add(r.leases.ttl)
+	return true
+}
+
+// LeaseExpiry returns when the lease on b runs out, reporting false if b
+// doesn't hold a lease.
+func (r *RandomByteSeq) LeaseExpiry(b byte) (time.Time, bool) {
+	r.lock()
+	defer r.unlock()
+
+	if r.leases == nil {
+		return time.Time{}, false
+	}
+	r.reclaim()
+	expiry := r.leases.expiry[b]
+	return expiry, !expiry.IsZero()
+}
+
+// newLeaseState returns the lease tracking for a sequence created with the
+// given settings, or nil if it doesn't use leases.
+func newLeaseState(ttl time.Duration, clock Clock) *leaseState {
+	if ttl == 0 {
+		return nil
+	}
+	return &leaseState{ttl: ttl, clock: clock}
+}
+
+func (l *leaseState) now() time.Time {
+	if l.clock == nil {
+		return time.Now()
+	}
+	return l.clock.Now()
+}
+
+// clone returns an independent copy of the lease tracking, which may be nil
+func (l *leaseState) clone() *leaseState {
+	if l == nil {
+		return nil
+	}
+	dup := *l
+	return &dup
+}
+
+// lease starts the lease on a newly drawn value, if leases are in use
+func (r *RandomByteSeq) lease(b byte) {
+	if r.leases == nil {
+		return
+	}
+	expiry := r.leases.now().Add(r.leases.ttl)
+	r.leases.expiry[b] = expiry
+	if r.leases.nextDue.IsZero() || expiry.Before(r.leases.nextDue) {
+		r.leases.nextDue = expiry
+	}
+}
+
+// resetLeases forgets every lease, for when the consumed values have been
+// replaced wholesale
+func (r *RandomByteSeq) resetLeases() {
+	if r.leases != nil {
+		*r.leases = leaseState{ttl: r.leases.ttl, clock: r.leases.clock, paused: r.leases.paused}
+	}
+}
+
+// endLease forgets the lease on a value that has gone back to the sequence
+func (r *RandomByteSeq) endLease(b byte) {
+	if r.leases != nil {
+		r.leases.expiry[b] = time.Time{}
+	}
+}
+
+// reclaim releases every value whose lease has run out. It only looks
+// through the leases when the earliest of them is due, so it is cheap to
+// call on every use of the sequence.
+func (r *RandomByteSeq) reclaim() {
+	if r.leases == nil || r.leases.paused || r.leases.nextDue.IsZero() {
+		return
+	}
+	now := r.leases.now()
+	if now.Before(r.leases.nextDue) {
+		return
+	}
+
+	r.leases.nextDue = time.Time{}
+	for i, expiry := range r.leases.expiry {
+		switch {
+		case expiry.IsZero():
+		case !now.Before(expiry) && r.valueHasBeenConsumed(byte(i)):
+			r.releaseByte(byte(i))
+		case !now.Before(expiry):
+			r.endLease(byte(i))
+		case r.leases.nextDue.IsZero() || expiry.Before(r.leases.nextDue):
+			r.leases.nextDue = expiry
+		}
+	}
+}
+
+// pauseReclaim reclaims expired leases once, then holds off reclaiming any
+// more until the returned function is called. Batch draws use it so that
+// values they have just drawn can't come back and be drawn again, or keep
+// the batch going forever, while it runs. The lock must be held throughout.
+func (r *RandomByteSeq) pauseReclaim() (resume func()) {
+	r.reclaim()
+	if r.leases == nil {
+		return func() {}
+	}
+	r.leases.paused = true
+	return func() { r.leases.paused = false }
+}
@@ -0,0 +1,161 @@
+package byteseq
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestLeasesAreReclaimedAfterTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	byteSeq := NewRangeSeq(0, 3, WithLeases(time.Minute), WithClock(clock))
+
+	values := byteSeq.Drain()
+	if byteSeq.HasMore() {
+		t.Fatalf("Expected the sequence to be exhausted")
+	}
+	if expiry, ok := byteSeq.LeaseExpiry(values[0]); !ok || !expiry.Equal(clock.now.Add(time.Minute)) {
+		t.Errorf("Expected a lease running out in a minute, got %v (%v)", expiry, ok)
+	}
+
+	// Keep one value alive, let the others lapse
+	clock.advance(40 * time.Second)
+	if !byteSeq.Renew(values[0]) {
+		t.Errorf("Expected the lease on 0x%02x to be renewed", values[0])
+	}
+	clock.advance(30 * time.Second)
+
+	if got := byteSeq.Remaining(); got != 3 {
+		t.Errorf("Expected the 3 lapsed values to be reclaimed, got %d", got)
+	}
+	if byteSeq.Renew(values[1]) {
+		t.Errorf("Expected a lapsed lease not to be renewable")
+	}
+	if !byteSeq.IsConsumed(values[0]) {
+		t.Errorf("Expected the renewed value 0x%02x to stay consumed", values[0])
+	}
+
+	clock.advance(time.Minute)
+	if got := byteSeq.Remaining(); got != 4 {
+		t.Errorf("Expected the renewed value to lapse in the end, got %d", got)
+	}
+	checkPool(t, byteSeq)
+}
+
+func TestLeasesEndOnRelease(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	byteSeq := NewRandomSeq(nil, WithLeases(time.Second), WithClock(clock))
+	_, _ = byteSeq.Consume(0x10)
+
+	value, _ := byteSeq.NextValue()
+	byteSeq.Release(value)
+	if _, ok := byteSeq.LeaseExpiry(value); ok {
+		t.Errorf("Expected releasing 0x%02x to end its lease", value)
+	}
+	if _, ok := byteSeq.LeaseExpiry(0x10); ok {
+		t.Errorf("Expected a consumed value not to be leased")
+	}
+
+	clock.advance(time.Hour)
+	if !byteSeq.IsConsumed(0x10) {
+		t.Errorf("Expected the consumed value to stay consumed")
+	}
+	if got := byteSeq.Remaining(); got != 255 {
+		t.Errorf("Expected 255 values, got %d", got)
+	}
+}
+
+func TestLeasesOptionChecksTTL(t *testing.T) {
+	if _, err := NewRandomSeqChecked(nil, WithLeases(0)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a zero ttl, got %v", err)
+	}
+}
+
+// tickingClock is a Clock that moves on by a second every time it is read,
+// so a lease can run out part way through a batch
+type tickingClock struct {
+	now time.Time
+}
+
+func (c *tickingClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+// checkWhole checks values holds every byte value exactly once
+func checkWhole(t *testing.T, values []byte) {
+	t.Helper()
+	if len(values) != 256 {
+		t.Fatalf("Expected 256 values, got %d", len(values))
+	}
+	var seen ByteSet
+	for _, b := range values {
+		if seen.Contains(b) {
+			t.Fatalf("Value 0x%02x was drawn twice", b)
+		}
+		seen.Add(b)
+	}
+}
+
+func TestDrainWithLeasesRunningOut(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithLeases(time.Nanosecond), WithClock(&tickingClock{}))
+
+	checkWhole(t, byteSeq.Drain())
+
+	// The leases have all run out by now, so everything comes back
+	if got := byteSeq.Remaining(); got != 256 {
+		t.Errorf("Expected every value to be reclaimed after the drain, got %d", got)
+	}
+}
+
+func TestWriteToWithLeasesRunningOut(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithLeases(time.Nanosecond), WithClock(&tickingClock{}))
+
+	var buf bytes.Buffer
+	written, err := byteSeq.WriteTo(&buf)
+	if err != nil || written != 256 {
+		t.Fatalf("Expected 256 values written, got %d (%v)", written, err)
+	}
+	checkWhole(t, buf.Bytes())
+}
+
+func TestNextNWithLeasesRunningOut(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithLeases(time.Nanosecond), WithClock(&tickingClock{}))
+
+	values, err := byteSeq.NextN(256)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkWhole(t, values)
+}
+
+func TestConstrainedDrawsReclaimLeases(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	byteSeq := NewRangeSeq(0, 2, WithLeases(time.Minute), WithClock(clock))
+	byteSeq.Drain()
+	clock.advance(2 * time.Minute)
+
+	if _, err := byteSeq.NextInRange(0, 2); err != nil {
+		t.Errorf("NextInRange: expected a reclaimed value, got %v", err)
+	}
+	if _, err := byteSeq.NextMatching(func(byte) bool { return true }); err != nil {
+		t.Errorf("NextMatching: expected a reclaimed value, got %v", err)
+	}
+	if _, err := byteSeq.NextFromSet(NewByteSet(0, 1, 2)); err != nil {
+		t.Errorf("NextFromSet: expected a reclaimed value, got %v", err)
+	}
+}
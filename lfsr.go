@@ -0,0 +1,65 @@
+package byteseq
+
+// An LFSRSeq returns every byte value once each by stepping an 8-bit linear
+// feedback shift register, in the same order as the LFSR ordering. Unlike a
+// RandomByteSeq it keeps no bitmap and no random source, only two bytes of
+// register and a count, which matters on memory-constrained targets. The
+// flip side is that it can't exclude, consume or release values, and its
+// order is fixed by the seed. It is not safe for concurrent use.
+type LFSRSeq struct {
+	seed  byte
+	state byte
+	drawn uint16
+}
+
+// NewLFSRSeq returns an LFSRSeq whose first value is seed. The cycle covers
+// all 256 values, zero included; callers that want the classic 255-value
+// cycle of non-zero values can simply drop the zero.
+func NewLFSRSeq(seed byte) *LFSRSeq {
+	return &LFSRSeq{seed: seed, state: seed}
+}
+
+// HasMore reports whether the sequence has any more values to return.
+func (l *LFSRSeq) HasMore() bool {
+	return l.drawn < 256
+}
+
+// Remaining returns how many values can still be drawn from the sequence.
+func (l *LFSRSeq) Remaining() int {
+	return 256 - int(l.drawn)
+}
+
+// NextValue returns the next value in the register's cycle, or ErrExhausted
+// once every value has been returned.
+func (l *LFSRSeq) NextValue() (byte, error) {
+	if l.drawn == 256 {
+		return 0, ErrExhausted
+	}
+	value := l.state
+	l.state = lfsrSucc(value)
+	l.drawn++
+	return value, nil
+}
+
+// Reset starts the cycle again from the seed.
+func (l *LFSRSeq) Reset() {
+	l.state = l.seed
+	l.drawn = 0
+}
+
+// lfsrSucc returns the value after x in the 256-value LFSR cycle. The
+// register is a Galois LFSR with taps 8, 6, 5 and 4, which has period 255.
+func lfsrSucc(x byte) byte {
+	if x == 0 {
+		return 1
+	}
+	lsb := x & 1
+	x >>= 1
+	if lsb != 0 {
+		x ^= 0xB8
+	}
+	if x == 1 {
+		return 0
+	}
+	return x
+}
@@ -0,0 +1,40 @@
+package byteseq
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLFSRSeqReturnsEveryValueOnce(t *testing.T) {
+	lfsrSeq := NewLFSRSeq(0x5A)
+
+	var seen [256]bool
+	var values []byte
+	for lfsrSeq.HasMore() {
+		value, _ := lfsrSeq.NextValue()
+		if seen[value] {
+			t.Fatalf("Value 0x%02x returned twice", value)
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+
+	if len(values) != 256 || values[0] != 0x5A {
+		t.Errorf("Expected 256 values starting at the seed, got %d starting at 0x%02x", len(values), values[0])
+	}
+	if _, err := lfsrSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
+
+	// It follows the same cycle as the LFSR ordering
+	ordered := NewRandomSeq(nil, WithOrdering(LFSR(0x5A))).Drain()
+	if !slices.Equal(values, ordered) {
+		t.Errorf("Expected the same order as the LFSR ordering")
+	}
+
+	lfsrSeq.Reset()
+	if value, _ := lfsrSeq.NextValue(); value != 0x5A || lfsrSeq.Remaining() != 255 {
+		t.Errorf("Expected Reset to start again from the seed, got 0x%02x", value)
+	}
+}
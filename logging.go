@@ -0,0 +1,38 @@
+package byteseq
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger has the sequence narrate what it does to logger, at debug
+// level: each value drawn or released, Reset, the start of each new epoch
+// under WithCycle, and draws that find the sequence exhausted. Each event
+// carries the value, where there is one, and the number of values
+// remaining, which makes it much easier to follow a value through a system
+// when chasing down a duplicate. Releases include values reclaimed when a
+// lease runs out, or handed back with Undo or Abort. Clones, snapshots and
+// shards made by Split log to the same logger. A nil logger turns logging
+// off.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// debug logs an event at debug level, if the sequence has a logger
+func (r *RandomByteSeq) debug(msg string, attrs ...slog.Attr) {
+	if r.logger == nil {
+		return
+	}
+	attrs = append(attrs, slog.Int("remaining", r.remainingValues))
+	r.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
+
+// debugValue logs an event about a single value
+func (r *RandomByteSeq) debugValue(msg string, b byte) {
+	if r.logger == nil {
+		return
+	}
+	r.debug(msg, slog.Int("value", int(b)))
+}
@@ -0,0 +1,70 @@
+package byteseq
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggerNarratesEvents(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	byteSeq := NewRangeSeq(7, 7, WithLogger(logger))
+
+	_, _ = byteSeq.NextValue()
+	_, _ = byteSeq.NextValue()
+	byteSeq.Release(7)
+	byteSeq.Reset()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		`msg="value drawn" value=7 remaining=0`,
+		`msg="sequence exhausted" remaining=0`,
+		`msg="value released" value=7 remaining=1`,
+		`msg="sequence reset" remaining=1`,
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d log lines, got %q", len(expected), lines)
+	}
+	for i, want := range expected {
+		if !strings.Contains(lines[i], "level=DEBUG "+want) {
+			t.Errorf("Expected log line %d to contain %q, got %q", i, want, lines[i])
+		}
+	}
+}
+
+func TestLoggerLogsNewEpochs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	byteSeq := NewRangeSeq(0, 1, WithCycle(), WithLogger(logger))
+
+	_, _ = byteSeq.NextN(3)
+	if !strings.Contains(buf.String(), `msg="epoch started" epoch=1 remaining=2`) {
+		t.Errorf("Expected the new epoch to be logged, got %q", buf.String())
+	}
+}
+
+func TestLoggerRespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	byteSeq := NewRandomSeq(nil, WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	_ = byteSeq.Drain()
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing logged above debug level, got %q", buf.String())
+	}
+}
+
+func TestLoggerLogsStolenValuesAsTransfers(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	group := NewShardGroup(NewRangeSeq(0, 3, WithLogger(logger)), 2)
+	group.Shard(0).Drain()
+
+	buf.Reset()
+	if moved := group.Steal(0); moved != 1 {
+		t.Fatalf("Expected to steal one value, stole %d", moved)
+	}
+	if log := buf.String(); !strings.Contains(log, `msg="value transferred"`) || strings.Contains(log, "value released") {
+		t.Errorf("Expected the stolen value to be logged as a transfer, got %q", log)
+	}
+}
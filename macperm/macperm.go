@@ -0,0 +1,108 @@
+// Package macperm emits random MAC addresses that never repeat, for giving
+// simulated hosts addresses that are guaranteed not to collide.
+//
+// Every address is unicast and locally administered, so it can't clash
+// with a vendor-assigned one. That leaves 46 of the 48 bits free, and a Seq
+// walks a counter through a keyed permutation of those 46 bits, the same
+// way ipv4perm does for a CIDR block. No address repeats until all 2^46
+// have been emitted, and the same key always gives the same order, so a
+// run can be repeated or resumed from the key and its position.
+package macperm
+
+import (
+	"crypto/rand"
+	"net"
+
+	"github.com/owenjklan/byteseq/perm"
+)
+
+// ErrExhausted is returned once every address has been emitted.
+var ErrExhausted = perm.ErrExhausted
+
+// KeySize is the size of the keys used by New, in bytes.
+const KeySize = perm.KeySize
+
+// Bits is the number of bits of an address that vary from one to the next.
+const Bits = 46
+
+// A Seq emits locally administered unicast MAC addresses in pseudo-random
+// order. It is not safe for concurrent use.
+type Seq struct {
+	perm *perm.Permutation
+	seq  *perm.Seq
+}
+
+// New returns a Seq over every locally administered unicast MAC address.
+// key must be KeySize bytes long; if it is nil, a random key is chosen,
+// giving a different order each time.
+func New(key []byte) (*Seq, error) {
+	if key == nil {
+		key = make([]byte, KeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := perm.NewBits(Bits, key)
+	if err != nil {
+		return nil, err
+	}
+	return &Seq{perm: p, seq: p.Seq()}, nil
+}
+
+// Size returns the number of addresses the sequence covers, 2^46.
+func (s *Seq) Size() uint64 {
+	return s.perm.Size()
+}
+
+// HasMore reports whether any addresses are left to emit.
+func (s *Seq) HasMore() bool {
+	return s.seq.HasMore()
+}
+
+// Remaining returns how many addresses are left to emit.
+func (s *Seq) Remaining() uint64 {
+	return s.seq.Remaining()
+}
+
+// Position returns how many addresses have been emitted so far. Together
+// with the key, it is all that's needed to resume a run with Seek.
+func (s *Seq) Position() uint64 {
+	return s.seq.Position()
+}
+
+// Seek moves the sequence to position, so the next address emitted is the
+// one that would have followed that many earlier addresses. Positions past
+// the end leave the sequence exhausted.
+func (s *Seq) Seek(position uint64) {
+	s.seq.Seek(position)
+}
+
+// NextValue returns the next address in the permuted order, or
+// ErrExhausted once every address has been emitted.
+func (s *Seq) NextValue() (net.HardwareAddr, error) {
+	v, err := s.seq.NextValue()
+	if err != nil {
+		return nil, err
+	}
+	return addr(v), nil
+}
+
+// At returns the address at index i of the permuted order, without moving
+// the sequence. i must be less than Size.
+func (s *Seq) At(i uint64) net.HardwareAddr {
+	return addr(s.perm.At(i))
+}
+
+// addr spreads the 46 bits of v over an address, setting the locally
+// administered bit and clearing the multicast bit of the first octet.
+func addr(v uint64) net.HardwareAddr {
+	return net.HardwareAddr{
+		byte(v>>40)<<2 | 0x02,
+		byte(v >> 32),
+		byte(v >> 24),
+		byte(v >> 16),
+		byte(v >> 8),
+		byte(v),
+	}
+}
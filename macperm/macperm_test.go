@@ -0,0 +1,58 @@
+package macperm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddressesAreUniqueAndLocal(t *testing.T) {
+	seq, err := New(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seq.Size() != 1<<46 {
+		t.Errorf("Expected 2^46 addresses, got %d", seq.Size())
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 100000; i++ {
+		mac, _ := seq.NextValue()
+		if mac[0]&0x01 != 0 || mac[0]&0x02 == 0 {
+			t.Fatalf("Address %s is not locally administered unicast", mac)
+		}
+		if seen[mac.String()] {
+			t.Fatalf("Address %s emitted twice", mac)
+		}
+		seen[mac.String()] = true
+	}
+}
+
+func TestResumeWithSameKey(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	first, _ := New(key)
+	for i := 0; i < 50; i++ {
+		_, _ = first.NextValue()
+	}
+
+	resumed, _ := New(key)
+	resumed.Seek(first.Position())
+	a, _ := first.NextValue()
+	b, _ := resumed.NextValue()
+	if a.String() != b.String() {
+		t.Errorf("Expected resumed sequence to give %s, got %s", a, b)
+	}
+	if at := resumed.At(50); at.String() != a.String() {
+		t.Errorf("Expected At(50) to give %s, got %s", a, at)
+	}
+}
+
+func TestExhausted(t *testing.T) {
+	seq, _ := New(nil)
+	seq.Seek(seq.Size())
+	if seq.HasMore() {
+		t.Errorf("Expected seeking to the end to exhaust the sequence")
+	}
+	if _, err := seq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package byteseq
+
+// MergeConsumed marks every value consumed in other as consumed in the
+// receiver too, so two sequences that were run independently can be
+// reconciled: afterwards the receiver never returns anything either of them
+// has already returned or excluded. other is left unchanged. The merged
+// values are drawn values as far as the receiver is concerned, not
+// exclusions, so a Reset brings them back.
+func (r *RandomByteSeq) MergeConsumed(other *RandomByteSeq) {
+	other.lock()
+	consumed := other.consumedBytes
+	other.unlock()
+
+	r.lock()
+	defer r.unlock()
+	r.mergeBitmap(consumed)
+}
+
+// Union returns a new sequence holding only the values that are unconsumed
+// in all of the given sequences. It takes its settings, such as the random
+// source and exclusions, from a clone of first, and merges the consumed
+// state of the others into it, leaving all of them unchanged.
+func Union(first *RandomByteSeq, others ...*RandomByteSeq) *RandomByteSeq {
+	merged := first.Clone()
+	for _, other := range others {
+		merged.MergeConsumed(other)
+	}
+	return merged
+}
+
+// mergeBitmap consumes every value set in the consumed bitmap
+func (r *RandomByteSeq) mergeBitmap(consumed ByteSet) {
+	for b := range consumed.All() {
+		if r.hasPeeked && r.peeked == b {
+			r.hasPeeked = false
+		}
+		r.consumeByte(b)
+	}
+}
@@ -0,0 +1,49 @@
+package byteseq
+
+import "testing"
+
+func TestMergeConsumed(t *testing.T) {
+	first := NewRandomSeq([]byte{0x01})
+	second := NewRandomSeq([]byte{0x01, 0x02})
+	drawn, _ := second.NextN(10)
+
+	first.MergeConsumed(second)
+
+	if got := first.Remaining(); got != 244 {
+		t.Errorf("Expected 244 values left after merging, got %d", got)
+	}
+	for _, value := range append(drawn, 0x01, 0x02) {
+		if !first.valueHasBeenConsumed(value) {
+			t.Errorf("Expected 0x%02x to be consumed after merging", value)
+		}
+	}
+	if got := second.Remaining(); got != 244 {
+		t.Errorf("Expected the other sequence to be left with 244 values, got %d", got)
+	}
+
+	// Merging a sequence into itself changes nothing
+	first.MergeConsumed(first)
+	if got := first.Remaining(); got != 244 {
+		t.Errorf("Expected 244 values left after merging with itself, got %d", got)
+	}
+	checkPool(t, first)
+}
+
+func TestUnion(t *testing.T) {
+	a := NewRandomSeq([]byte{0x00, 0x01})
+	b := NewRandomSeq([]byte{0x01, 0x02})
+	c := NewRandomSeq([]byte{0x03})
+
+	union := Union(a, b, c)
+	if got := union.Remaining(); got != 252 {
+		t.Errorf("Expected 252 values in the union, got %d", got)
+	}
+	for _, value := range union.Drain() {
+		if value <= 0x03 {
+			t.Errorf("Union returned 0x%02x, which is consumed in one of its inputs", value)
+		}
+	}
+	if got := a.Remaining(); got != 254 {
+		t.Errorf("Expected Union to leave its inputs unchanged, but the first has %d values", got)
+	}
+}
@@ -0,0 +1,177 @@
+// Package metrics wraps a byteseq.RandomByteSeq with counters for how it is
+// used, for dashboards on how fast a value space is being consumed. The
+// counters are exposed through expvar and as a Prometheus collector:
+//
+//	seq := metrics.New("session_ids", byteseq.NewSafeRandomSeq(nil))
+//	expvar.Publish("session_ids", seq.Expvar())
+//	prometheus.MustRegister(seq)
+//
+// It is a module of its own, so only programs that import it depend on the
+// Prometheus client.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync/atomic"
+
+	"github.com/owenjklan/byteseq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Check Seq keeps satisfying the collector interface
+var _ prometheus.Collector = (*Seq)(nil)
+
+// A Seq is a RandomByteSeq that counts its draws, releases, rejections and
+// the draws that found it exhausted. It is safe for concurrent use if the
+// sequence it wraps is.
+type Seq struct {
+	seq *byteseq.RandomByteSeq
+
+	draws       atomic.Uint64
+	releases    atomic.Uint64
+	rejections  atomic.Uint64
+	exhaustions atomic.Uint64
+
+	drawsDesc       *prometheus.Desc
+	releasesDesc    *prometheus.Desc
+	rejectionsDesc  *prometheus.Desc
+	exhaustionsDesc *prometheus.Desc
+	remainingDesc   *prometheus.Desc
+}
+
+// New returns a Seq counting the use of seq. name tells the sequence's
+// metrics apart from those of others, in the seq label of the Prometheus
+// metrics. Only use made through the Seq is counted, so the wrapped
+// sequence shouldn't be used directly alongside it.
+func New(name string, seq *byteseq.RandomByteSeq) *Seq {
+	labels := prometheus.Labels{"seq": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("byteseq", "", metric), help, nil, labels)
+	}
+
+	return &Seq{
+		seq:             seq,
+		drawsDesc:       desc("draws_total", "Values drawn from the sequence."),
+		releasesDesc:    desc("releases_total", "Values released back to the sequence."),
+		rejectionsDesc:  desc("rejections_total", "Peeked candidates passed over with Reject."),
+		exhaustionsDesc: desc("exhaustions_total", "Draws that found the sequence exhausted."),
+		remainingDesc:   desc("remaining", "Values left to draw."),
+	}
+}
+
+// Unwrap returns the sequence the Seq counts the use of.
+func (s *Seq) Unwrap() *byteseq.RandomByteSeq {
+	return s.seq
+}
+
+// NextValue draws a value, as RandomByteSeq.NextValue does.
+func (s *Seq) NextValue() (byte, error) {
+	value, err := s.seq.NextValue()
+	s.countDraw(err)
+	return value, err
+}
+
+// NextValueCtx draws a value, as RandomByteSeq.NextValueCtx does.
+func (s *Seq) NextValueCtx(ctx context.Context) (byte, error) {
+	value, err := s.seq.NextValueCtx(ctx)
+	s.countDraw(err)
+	return value, err
+}
+
+// NextN draws up to n values, as RandomByteSeq.NextN does.
+func (s *Seq) NextN(n int) ([]byte, error) {
+	values, err := s.seq.NextN(n)
+	s.draws.Add(uint64(len(values)))
+	if errors.Is(err, byteseq.ErrExhausted) {
+		s.exhaustions.Add(1)
+	}
+	return values, err
+}
+
+// Peek returns the next candidate without drawing it, as RandomByteSeq.Peek
+// does. Only once it is accepted does it count as a draw.
+func (s *Seq) Peek() (byte, error) {
+	return s.seq.Peek()
+}
+
+// Accept draws the candidate reported by Peek, as RandomByteSeq.Accept does.
+func (s *Seq) Accept() (byte, error) {
+	value, err := s.seq.Accept()
+	s.countDraw(err)
+	return value, err
+}
+
+// Reject passes over the candidate reported by Peek, as RandomByteSeq.Reject
+// does, counting a rejection.
+func (s *Seq) Reject() {
+	s.seq.Reject()
+	s.rejections.Add(1)
+}
+
+// Release returns b to the sequence, as RandomByteSeq.Release does. Only
+// values that are actually released are counted.
+func (s *Seq) Release(b byte) bool {
+	released := s.seq.Release(b)
+	if released {
+		s.releases.Add(1)
+	}
+	return released
+}
+
+// Remaining returns how many values are left to draw.
+func (s *Seq) Remaining() int {
+	return s.seq.Remaining()
+}
+
+// HasMore reports whether there are values left to draw.
+func (s *Seq) HasMore() bool {
+	return s.seq.HasMore()
+}
+
+// Expvar returns the counters as an expvar.Var, for publishing with
+// expvar.Publish. It is shown as a JSON object of the counts, along with
+// the number of values remaining.
+func (s *Seq) Expvar() expvar.Var {
+	return expvar.Func(func() any {
+		return map[string]any{
+			"draws":       s.draws.Load(),
+			"releases":    s.releases.Load(),
+			"rejections":  s.rejections.Load(),
+			"exhaustions": s.exhaustions.Load(),
+			"remaining":   s.seq.Remaining(),
+		}
+	})
+}
+
+// Describe implements prometheus.Collector.
+func (s *Seq) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.drawsDesc
+	ch <- s.releasesDesc
+	ch <- s.rejectionsDesc
+	ch <- s.exhaustionsDesc
+	ch <- s.remainingDesc
+}
+
+// Collect implements prometheus.Collector.
+func (s *Seq) Collect(ch chan<- prometheus.Metric) {
+	counter := func(desc *prometheus.Desc, v *atomic.Uint64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v.Load()))
+	}
+	counter(s.drawsDesc, &s.draws)
+	counter(s.releasesDesc, &s.releases)
+	counter(s.rejectionsDesc, &s.rejections)
+	counter(s.exhaustionsDesc, &s.exhaustions)
+	ch <- prometheus.MustNewConstMetric(s.remainingDesc, prometheus.GaugeValue, float64(s.seq.Remaining()))
+}
+
+// countDraw counts the outcome of drawing a single value
+func (s *Seq) countDraw(err error) {
+	switch {
+	case err == nil:
+		s.draws.Add(1)
+	case errors.Is(err, byteseq.ErrExhausted):
+		s.exhaustions.Add(1)
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/owenjklan/byteseq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCounters(t *testing.T) {
+	seq := New("test", byteseq.NewRangeSeq(0, 3))
+
+	value, _ := seq.NextValue()
+	_, _ = seq.NextN(2)
+	seq.Release(value)
+	seq.Release(value)
+	_, _ = seq.Peek()
+	seq.Reject()
+	_, _ = seq.NextN(5)
+	_, _ = seq.NextValue()
+
+	var counts map[string]int
+	if err := json.Unmarshal([]byte(seq.Expvar().String()), &counts); err != nil {
+		t.Fatalf("Unexpected error decoding expvar: %v", err)
+	}
+	expected := map[string]int{"draws": 5, "releases": 1, "rejections": 1, "exhaustions": 2, "remaining": 0}
+	for name, count := range expected {
+		if counts[name] != count {
+			t.Errorf("Expected %s to be %d, got %d", name, count, counts[name])
+		}
+	}
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	seq := New("ids", byteseq.NewRangeSeq(0, 9))
+	_, _ = seq.NextN(3)
+
+	expected := `
+# HELP byteseq_draws_total Values drawn from the sequence.
+# TYPE byteseq_draws_total counter
+byteseq_draws_total{seq="ids"} 3
+# HELP byteseq_remaining Values left to draw.
+# TYPE byteseq_remaining gauge
+byteseq_remaining{seq="ids"} 7
+`
+	if err := testutil.CollectAndCompare(seq, strings.NewReader(expected), "byteseq_draws_total", "byteseq_remaining"); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+	if count := testutil.CollectAndCount(seq); count != 5 {
+		t.Errorf("Expected 5 metrics, got %d", count)
+	}
+}
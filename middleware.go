@@ -0,0 +1,60 @@
+package byteseq
+
+import "context"
+
+// A DrawFunc draws a single value from a sequence, as NextValueCtx does.
+type DrawFunc func(ctx context.Context) (byte, error)
+
+// A Middleware wraps the way a sequence draws a value, for cross-cutting
+// concerns such as tracing, retry accounting or policy checks. It is given
+// the next DrawFunc in the chain and returns one that calls it, doing its
+// own work before or after:
+//
+//	func countDraws(next byteseq.DrawFunc) byteseq.DrawFunc {
+//		return func(ctx context.Context) (byte, error) {
+//			value, err := next(ctx)
+//			if err == nil {
+//				draws.Add(1)
+//			}
+//			return value, err
+//		}
+//	}
+//
+// A Middleware may call next more than once, or not at all.
+type Middleware func(next DrawFunc) DrawFunc
+
+// WithMiddleware stacks mw around the sequence's draws, the first given
+// being the outermost, so it sees each draw first and its result last. The
+// option can be given more than once, adding to the stack. Middleware wraps
+// NextValue and NextValueCtx, and so everything built on them: MustNextValue,
+// Values, ForEach, Pull and ReadByte. The other ways of drawing, such as
+// the batch and constrained draws, TryNextValue, Peek and Stream, go
+// straight to the sequence.
+//
+// The middleware sees values after any transform, as the caller does. It
+// runs without the sequence's lock held, so it may use the sequence, for
+// instance to Release a value it rejects; it must not call NextValue or
+// NextValueCtx itself, though, as that would go through the middleware
+// again. Clones, snapshots and shards made by Split keep the middleware.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *config) {
+		for _, m := range mw {
+			if m == nil {
+				c.fail("middleware is nil")
+				return
+			}
+		}
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// throughMiddleware draws a value by way of the middleware stack
+func (r *RandomByteSeq) throughMiddleware(ctx context.Context) (byte, error) {
+	draw := DrawFunc(func(ctx context.Context) (byte, error) {
+		return r.output(r.nextRaw(ctx))
+	})
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		draw = r.middleware[i](draw)
+	}
+	return draw(ctx)
+}
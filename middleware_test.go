@@ -0,0 +1,89 @@
+package byteseq
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	tag := func(name string) Middleware {
+		return func(next DrawFunc) DrawFunc {
+			return func(ctx context.Context) (byte, error) {
+				calls = append(calls, name+" before")
+				value, err := next(ctx)
+				calls = append(calls, name+" after")
+				return value, err
+			}
+		}
+	}
+	byteSeq := NewRandomSeq(nil, WithMiddleware(tag("outer")), WithMiddleware(tag("inner")))
+
+	_, _ = byteSeq.NextValue()
+	expected := []string{"outer before", "inner before", "inner after", "outer after"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+			break
+		}
+	}
+}
+
+func TestMiddlewareCanRejectValues(t *testing.T) {
+	rejected := 0
+	evenOnly := func(next DrawFunc) DrawFunc {
+		return func(ctx context.Context) (byte, error) {
+			for {
+				value, err := next(ctx)
+				if err != nil || value%2 == 0 {
+					return value, err
+				}
+				rejected++
+			}
+		}
+	}
+	byteSeq := NewRandomSeq(nil, WithMiddleware(evenOnly))
+
+	count := 0
+	for value := range byteSeq.Values() {
+		if value%2 != 0 {
+			t.Errorf("Expected only even values, got 0x%02x", value)
+		}
+		count++
+	}
+	if count != 128 || rejected != 128 {
+		t.Errorf("Expected 128 even values and 128 rejections, got %d and %d", count, rejected)
+	}
+}
+
+func TestMiddlewareSeesContext(t *testing.T) {
+	type key struct{}
+	var seen any
+	peek := func(next DrawFunc) DrawFunc {
+		return func(ctx context.Context) (byte, error) {
+			seen = ctx.Value(key{})
+			return next(ctx)
+		}
+	}
+	byteSeq := NewRandomSeq(nil, WithMiddleware(peek))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "traced"))
+	_, _ = byteSeq.NextValueCtx(ctx)
+	if seen != "traced" {
+		t.Errorf("Expected the middleware to be given the caller's context, got %v", seen)
+	}
+	cancel()
+	if _, err := byteSeq.NextValueCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context error to come back through the middleware, got %v", err)
+	}
+}
+
+func TestMiddlewareOptionChecks(t *testing.T) {
+	if _, err := NewRandomSeqChecked(nil, WithMiddleware(nil)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for nil middleware, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package byteseq
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrDuplicateValue is returned by NewRandomSeqChecked when a value is
+// excluded more than once.
+var ErrDuplicateValue = errors.New("value excluded more than once")
+
+// ErrInvalidOption is returned by NewRandomSeqChecked when an option was
+// given a setting it can't use.
+var ErrInvalidOption = errors.New("invalid option")
+
+// An Option configures a RandomByteSeq when it is created. Options are
+// applied in order, so where two options set the same thing, such as the
+// random source, the last one wins.
+type Option func(*config)
+
+// config collects the settings from the options given to a constructor
+type config struct {
+	excluded []byte
+	filters  []func(byte) bool
+	src      Source
+	locking  bool
+	weights  *[256]float64
+	limiter  *rate.Limiter
+	history  bool
+	policy   ExhaustionPolicy
+
+	transform  func(byte) byte
+	ordering   Ordering
+	middleware []Middleware
+	logger     *slog.Logger
+	leaseTTL   time.Duration
+	clock      Clock
+
+	watermarks  []watermark
+	onConsume   []func(byte, int)
+	onExhausted []func()
+
+	err error // the first problem found with an option
+}
+
+// fail records a problem with an option, keeping the first one found
+func (c *config) fail(format string, args ...any) {
+	if c.err == nil {
+		c.err = fmt.Errorf("%w: "+format, append([]any{ErrInvalidOption}, args...)...)
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithExcluded excludes the given values from the sequence, in addition to
+// any passed to the constructor directly.
+func WithExcluded(values ...byte) Option {
+	return func(c *config) {
+		c.excluded = append(c.excluded, values...)
+	}
+}
+
+// WithExcludedRange excludes every value from lo to hi inclusive, so
+// WithExcludedRange(0x00, 0x1F) leaves out the ASCII control characters.
+// lo must not be greater than hi.
+func WithExcludedRange(lo, hi byte) Option {
+	return func(c *config) {
+		if lo > hi {
+			c.fail("excluded range 0x%02x-0x%02x is inverted", lo, hi)
+			return
+		}
+		for i := int(lo); i <= int(hi); i++ {
+			c.excluded = append(c.excluded, byte(i))
+		}
+	}
+}
+
+// WithFilter excludes every value for which keep returns false, for when a
+// rule is easier to state than the values it rules out:
+//
+//	seq := byteseq.NewRandomSeq(nil, byteseq.WithFilter(func(b byte) bool { return b < 0x80 }))
+//
+// keep is called once for each value when the sequence is created, and the
+// values it rejects are treated like any other exclusion. Where more than
+// one filter is given, a value must pass all of them.
+func WithFilter(keep func(byte) bool) Option {
+	return func(c *config) {
+		if keep == nil {
+			c.fail("filter is nil")
+			return
+		}
+		c.filters = append(c.filters, keep)
+	}
+}
+
+// WithSeed makes the sequence draw from its own PCG generator seeded with
+// seed, as NewRandomSeqSeeded does, so the order is reproducible.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.src = rand.NewPCG(uint64(seed), 0)
+	}
+}
+
+// WithChaCha8 makes the sequence draw from a ChaCha8 generator keyed with
+// seed, as NewRandomSeqChaCha8 does.
+func WithChaCha8(seed [32]byte) Option {
+	return func(c *config) {
+		c.src = rand.NewChaCha8(seed)
+	}
+}
+
+// WithLabel makes the sequence draw from a ChaCha8 generator keyed with the
+// SHA-256 hash of label, as NewRandomSeqNamed does. The ChaCha8 output is
+// fixed by math/rand/v2, so a label gives the same order across runs,
+// machines and releases of Go.
+func WithLabel(label string) Option {
+	return WithChaCha8(sha256.Sum256([]byte(label)))
+}
+
+// WithSource makes the sequence draw from src instead of the package-global
+// source. A nil src selects the package-global source.
+func WithSource(src Source) Option {
+	return func(c *config) {
+		c.src = src
+	}
+}
+
+// WithLocking makes the sequence safe for concurrent use, with the
+// guarantees described for NewSafeRandomSeq.
+func WithLocking() Option {
+	return func(c *config) {
+		c.locking = true
+	}
+}
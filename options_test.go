@@ -0,0 +1,122 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOptionsCombine(t *testing.T) {
+	first := NewRandomSeq([]byte{0x00}, WithExcluded(0xFE, 0xFF), WithSeed(8))
+	second := NewRandomSeqSeeded(8, []byte{0x00, 0xFE, 0xFF})
+
+	if first.Remaining() != 253 {
+		t.Errorf("Expected 253 values remaining but got %d", first.Remaining())
+	}
+	for first.HasMore() {
+		a, _ := first.NextValue()
+		b, _ := second.NextValue()
+		if a != b {
+			t.Fatalf("Expected WithSeed to match NewRandomSeqSeeded, got 0x%02x and 0x%02x", a, b)
+		}
+	}
+}
+
+func TestLastSourceOptionWins(t *testing.T) {
+	src := &countingSource{rng: nil}
+	byteSeq := NewRandomSeq(nil, WithSource(src), WithSeed(1))
+	_, _ = byteSeq.NextValue()
+
+	if src.calls != 0 {
+		t.Errorf("Expected the later WithSeed to replace the source")
+	}
+}
+
+func TestWithLocking(t *testing.T) {
+	if NewRandomSeq(nil).mu != nil {
+		t.Errorf("Expected no lock by default")
+	}
+	if NewRangeSeq(1, 2, WithLocking()).mu == nil {
+		t.Errorf("Expected WithLocking to add a lock")
+	}
+}
+
+func TestCheckedConstructorRejectsDuplicates(t *testing.T) {
+	if _, err := NewRandomSeqChecked([]byte{0x01, 0x02, 0x01}); !errors.Is(err, ErrDuplicateValue) {
+		t.Errorf("Expected ErrDuplicateValue but got %v", err)
+	}
+	if _, err := NewRandomSeqChecked([]byte{0x01}, WithExcluded(0x01)); !errors.Is(err, ErrDuplicateValue) {
+		t.Errorf("Expected ErrDuplicateValue across exclusion sources but got %v", err)
+	}
+
+	byteSeq, err := NewRandomSeqChecked([]byte{0x01, 0x02}, WithExcluded(0x03))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if byteSeq.Remaining() != 253 {
+		t.Errorf("Expected 253 values remaining but got %d", byteSeq.Remaining())
+	}
+}
+
+func TestCheckedConstructorReportsBadOptions(t *testing.T) {
+	bad := func(c *config) { c.fail("broken setting %d", 7) }
+
+	if _, err := NewRandomSeqChecked(nil, bad); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption but got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected NewRandomSeq to panic on a bad option")
+		}
+	}()
+	NewRandomSeq(nil, bad)
+}
+
+func TestWithExcludedRange(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithExcludedRange(0x00, 0x1F), WithExcluded(0x7F))
+	if byteSeq.Remaining() != 256-33 {
+		t.Errorf("Expected %d values remaining but got %d", 256-33, byteSeq.Remaining())
+	}
+	for value := range byteSeq.Values() {
+		if value <= 0x1F || value == 0x7F {
+			t.Errorf("Excluded value 0x%02x was returned", value)
+		}
+	}
+
+	if NewRandomSeq(nil, WithExcludedRange(0x00, 0xFF)).HasMore() {
+		t.Errorf("Expected excluding the full range to leave nothing")
+	}
+	if _, err := NewRandomSeqChecked(nil, WithExcludedRange(0x20, 0x10)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for an inverted range but got %v", err)
+	}
+}
+
+func TestWithFilter(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00},
+		WithFilter(func(b byte) bool { return b < 0x80 }),
+		WithFilter(func(b byte) bool { return b%2 == 0 }),
+	)
+
+	if got := byteSeq.Remaining(); got != 63 {
+		t.Errorf("Expected 63 even values below 0x80 other than 0x00, got %d", got)
+	}
+	for _, value := range byteSeq.Drain() {
+		if value >= 0x80 || value%2 != 0 {
+			t.Errorf("Filtered value 0x%02x was returned", value)
+		}
+	}
+
+	// Filtered values are exclusions, so they survive a Reset
+	byteSeq.Reset()
+	if got := byteSeq.Remaining(); got != 63 {
+		t.Errorf("Expected Reset to keep the filter, got %d values", got)
+	}
+
+	// A filter overlapping the exclusions isn't a duplicate
+	if _, err := NewRandomSeqChecked([]byte{0xFF}, WithFilter(func(b byte) bool { return b != 0xFF })); err != nil {
+		t.Errorf("Expected a filter to overlap exclusions freely, got %v", err)
+	}
+	if _, err := NewRandomSeqChecked(nil, WithFilter(nil)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a nil filter, got %v", err)
+	}
+}
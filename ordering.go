@@ -0,0 +1,236 @@
+package byteseq
+
+import (
+	"math/bits"
+
+	"github.com/owenjklan/byteseq/internal/randutil"
+)
+
+// An Ordering decides which value a sequence hands out next, for when a
+// random order isn't wanted, such as tests that need to cover the values
+// deterministically. The sequence keeps track of which values have been
+// consumed, by whatever means, and each time it needs a value it asks the
+// Ordering to pick one of those that are left.
+//
+// Next is given the values that are available, which is never empty, and
+// must return one of them. It is called with the sequence's lock held, so
+// it must not use the sequence.
+type Ordering interface {
+	Next(available ByteSet) byte
+}
+
+// A CloningOrdering is an Ordering that keeps state, such as its place in a
+// cycle, and can copy it. Clone must return an Ordering that carries on from
+// the same place without sharing any state with the original.
+type CloningOrdering interface {
+	Ordering
+	Clone() Ordering
+}
+
+// WithOrdering makes the sequence hand its values out in the order chosen
+// by ordering, rather than at random. It applies to every kind of draw,
+// including the constrained ones, where ordering picks among the values
+// that meet the constraint. It takes the place of the random choice, so
+// WithWeights has no effect alongside it. Clones, snapshots and shards made
+// by Split get their own copy of an ordering that implements
+// CloningOrdering, as LFSR, Multiplicative and VanDerCorput do. Any other
+// ordering is shared with the original, so one that keeps state must
+// implement CloningOrdering to be safe in sequences used side by side. A nil
+// ordering selects the usual random order.
+func WithOrdering(ordering Ordering) Option {
+	return func(c *config) {
+		c.ordering = ordering
+	}
+}
+
+// WithLowestFirst makes the sequence an allocator of the smallest free
+// value: every draw hands out the numerically lowest value that isn't
+// consumed, so a value given back with Release is the next one drawn if
+// nothing lower is free. It is shorthand for WithOrdering(Sequential()),
+// for protocols that call for smallest-free-ID assignment, and works with
+// the same bitmap, Consume and Release as a random sequence.
+func WithLowestFirst() Option {
+	return WithOrdering(Sequential())
+}
+
+// Random returns an Ordering that picks uniformly at random from the values
+// available, drawing from src, or the package-global source if src is nil.
+// It gives the same kind of order as a sequence without WithOrdering, for
+// ordering-aware code that wants to choose between strategies.
+func Random(src Source) Ordering {
+	return randomOrdering{src: src}
+}
+
+type randomOrdering struct {
+	src Source
+}
+
+func (o randomOrdering) Next(available ByteSet) byte {
+	return available.nth(int(randutil.Uint64n(o.src, uint64(available.Count()))))
+}
+
+// Sequential returns an Ordering that always picks the smallest value
+// available, so a sequence hands its values out in ascending order.
+func Sequential() Ordering {
+	return sequentialOrdering{}
+}
+
+type sequentialOrdering struct{}
+
+func (sequentialOrdering) Next(available ByteSet) byte {
+	return available.nth(0)
+}
+
+// Reverse returns an Ordering that always picks the largest value
+// available, so a sequence hands its values out in descending order.
+func Reverse() Ordering {
+	return reverseOrdering{}
+}
+
+type reverseOrdering struct{}
+
+func (reverseOrdering) Next(available ByteSet) byte {
+	for i := len(available) - 1; ; i-- {
+		if available[i] != 0 {
+			return byte(i<<3 | (bits.Len8(available[i]) - 1))
+		}
+	}
+}
+
+// LFSR returns an Ordering that follows the cycle of an 8-bit linear feedback
+// shift register, starting at seed, skipping values that aren't available.
+// The register's maximal cycle covers the 255 non-zero values, and zero is
+// slotted in just before 1 to complete it. The order looks scrambled but is
+// entirely predictable, which suits hardware test patterns. The returned
+// Ordering keeps its place in the cycle, so each sequence needs its own.
+func LFSR(seed byte) Ordering {
+	return &lfsrOrdering{next: seed}
+}
+
+type lfsrOrdering struct {
+	next byte // the first value to try on the next call
+}
+
+func (o *lfsrOrdering) Next(available ByteSet) byte {
+	for !available.Contains(o.next) {
+		o.next = lfsrSucc(o.next)
+	}
+	value := o.next
+	o.next = lfsrSucc(value)
+	return value
+}
+
+func (o *lfsrOrdering) Clone() Ordering {
+	dup := *o
+	return &dup
+}
+
+// Multiplicative returns an Ordering that steps through the multiplicative
+// group of integers modulo 257, which has exactly 256 members, multiplying
+// by a at each step and handing out one less than each member. Starting at
+// seed, it gives a well-scrambled order that is fully described by two
+// numbers, which makes a run easy to replay. a must be a primitive root
+// modulo 257, such as 3, so that it reaches every member; there are 128 of
+// them to choose from. Multiplicative panics if a is not one. Like LFSR, the
+// returned Ordering keeps its place, so each sequence needs its own.
+func Multiplicative(a, seed byte) Ordering {
+	if !isPrimitiveRoot257(uint32(a)) {
+		panic("byteseq: multiplier is not a primitive root modulo 257")
+	}
+	return &multiplicativeOrdering{a: uint32(a), next: uint32(seed) + 1}
+}
+
+type multiplicativeOrdering struct {
+	a    uint32
+	next uint32 // the group member to try on the next call, from 1 to 256
+}
+
+func (o *multiplicativeOrdering) Next(available ByteSet) byte {
+	for !available.Contains(byte(o.next - 1)) {
+		o.next = o.next * o.a % 257
+	}
+	value := byte(o.next - 1)
+	o.next = o.next * o.a % 257
+	return value
+}
+
+func (o *multiplicativeOrdering) Clone() Ordering {
+	dup := *o
+	return &dup
+}
+
+// isPrimitiveRoot257 reports whether a generates the multiplicative group
+// modulo 257. The group's order is 2^8, so a does exactly when a^128 isn't 1.
+func isPrimitiveRoot257(a uint32) bool {
+	if a%257 == 0 {
+		return false
+	}
+	x := a % 257
+	for i := 0; i < 7; i++ {
+		x = x * x % 257
+	}
+	return x != 1
+}
+
+// VanDerCorput returns an Ordering that hands values out in bit-reversed
+// counting order: 0x00, 0x80, 0x40, 0xC0, 0x20 and so on, the base 2 van der
+// Corput sequence scaled to a byte. Each value lands in the middle of the
+// largest gap left by those before it, so however few values are drawn,
+// they are spread as evenly as they can be across 0-255. That makes for much
+// better early coverage than a random order in sampling-style work. Like
+// LFSR, the returned Ordering keeps its place, so each sequence needs its
+// own.
+func VanDerCorput() Ordering {
+	return &vanDerCorputOrdering{}
+}
+
+type vanDerCorputOrdering struct {
+	count byte // the count whose reversal to try on the next call
+}
+
+func (o *vanDerCorputOrdering) Next(available ByteSet) byte {
+	for !available.Contains(bits.Reverse8(o.count)) {
+		o.count++
+	}
+	value := bits.Reverse8(o.count)
+	o.count++
+	return value
+}
+
+func (o *vanDerCorputOrdering) Clone() Ordering {
+	dup := *o
+	return &dup
+}
+
+// cloneOrdering copies an ordering that knows how to copy itself, so a
+// cloned sequence doesn't disturb the order of the original. Other
+// orderings are shared.
+func cloneOrdering(o Ordering) Ordering {
+	if c, ok := o.(CloningOrdering); ok {
+		return c.Clone()
+	}
+	return o
+}
+
+// choose picks which of the candidates to hand out, using the ordering if
+// there is one. candidates must not be empty.
+func (r *RandomByteSeq) choose(candidates ByteSet) byte {
+	if r.ordering == nil {
+		return candidates.nth(r.intN(candidates.Count()))
+	}
+
+	value := r.ordering.Next(candidates)
+	if !candidates.Contains(value) {
+		panic("byteseq: ordering returned a value that isn't available")
+	}
+	return value
+}
+
+// unconsumed returns the set of values that haven't been consumed
+func (r *RandomByteSeq) unconsumed() ByteSet {
+	var all ByteSet
+	for i := range all {
+		all[i] = 0xFF
+	}
+	return all.Difference(r.consumedBytes)
+}
@@ -0,0 +1,167 @@
+package byteseq
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSequentialAndReverseOrderings(t *testing.T) {
+	ascending := NewRandomSeq([]byte{0x05}, WithOrdering(Sequential())).Drain()
+	if !slices.IsSorted(ascending) || len(ascending) != 255 {
+		t.Errorf("Expected 255 values in ascending order, got %v", ascending)
+	}
+
+	descending := NewRangeSeq(0x10, 0x20, WithOrdering(Reverse())).Drain()
+	for i, value := range descending {
+		if want := byte(0x20 - i); value != want {
+			t.Fatalf("Expected 0x%02x at position %d, got 0x%02x", want, i, value)
+		}
+	}
+}
+
+func TestOrderingSkipsConsumedValues(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithOrdering(Sequential()))
+	_, _ = byteSeq.Consume(0x00)
+	_, _ = byteSeq.Consume(0x01)
+
+	if value, _ := byteSeq.NextValue(); value != 0x02 {
+		t.Errorf("Expected 0x02 after consuming 0x00 and 0x01, got 0x%02x", value)
+	}
+	if value, _ := byteSeq.NextInRange(0x80, 0xFF); value != 0x80 {
+		t.Errorf("Expected the ordering to apply to constrained draws, got 0x%02x", value)
+	}
+}
+
+func TestLFSROrderingCoversEveryValue(t *testing.T) {
+	values := NewRandomSeq(nil, WithOrdering(LFSR(0x01))).Drain()
+	if len(values) != 256 {
+		t.Fatalf("Expected 256 values, got %d", len(values))
+	}
+	if values[0] != 0x01 || values[255] != 0x00 {
+		t.Errorf("Expected the cycle to run from 0x01 round to 0x00, got 0x%02x to 0x%02x", values[0], values[255])
+	}
+
+	// The order is fixed by the seed
+	again := NewRandomSeq(nil, WithOrdering(LFSR(0x01))).Drain()
+	if !slices.Equal(values, again) {
+		t.Errorf("Expected the same seed to give the same order")
+	}
+	if slices.IsSorted(values) {
+		t.Errorf("Expected the LFSR order not to be sorted")
+	}
+}
+
+func TestRandomOrderingIsReproducibleWithSource(t *testing.T) {
+	a := NewRandomSeq(nil, WithOrdering(Random(rand.NewPCG(1, 0)))).Drain()
+	b := NewRandomSeq(nil, WithOrdering(Random(rand.NewPCG(1, 0)))).Drain()
+	if !slices.Equal(a, b) {
+		t.Errorf("Expected identically seeded random orderings to match")
+	}
+	if len(a) != 256 {
+		t.Errorf("Expected 256 values, got %d", len(a))
+	}
+}
+
+func TestMultiplicativeOrdering(t *testing.T) {
+	values := NewRandomSeq(nil, WithOrdering(Multiplicative(3, 0x00))).Drain()
+	if len(values) != 256 {
+		t.Fatalf("Expected 256 values, got %d", len(values))
+	}
+
+	// 1, 3, 9, 27, 81, 243, 729 % 257 = 215, less one each
+	want := []byte{0, 2, 8, 26, 80, 242, 214}
+	if !slices.Equal(values[:len(want)], want) {
+		t.Errorf("Expected the order to start %v, got %v", want, values[:len(want)])
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a multiplier that isn't a primitive root to panic")
+		}
+	}()
+	Multiplicative(2, 0)
+}
+
+func TestVanDerCorputOrdering(t *testing.T) {
+	values := NewRandomSeq(nil, WithOrdering(VanDerCorput())).Drain()
+	if len(values) != 256 {
+		t.Fatalf("Expected 256 values, got %d", len(values))
+	}
+
+	want := []byte{0x00, 0x80, 0x40, 0xC0, 0x20, 0xA0, 0x60, 0xE0}
+	if !slices.Equal(values[:len(want)], want) {
+		t.Errorf("Expected the order to start %v, got %v", want, values[:len(want)])
+	}
+
+	// Any prefix of 2^n values is spread evenly, one per bucket of 256/2^n
+	for n := 1; n <= 8; n++ {
+		size := 1 << n
+		var buckets [256]bool
+		for _, value := range values[:size] {
+			bucket := int(value) / (256 / size)
+			if buckets[bucket] {
+				t.Errorf("First %d values put two in bucket %d", size, bucket)
+			}
+			buckets[bucket] = true
+		}
+	}
+}
+
+func TestLowestFirstReusesFreedValues(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00}, WithLowestFirst())
+	_, _ = byteSeq.Consume(0x02)
+
+	for _, expected := range []byte{0x01, 0x03, 0x04} {
+		if value, _ := byteSeq.NextValue(); value != expected {
+			t.Errorf("Expected 0x%02x, got 0x%02x", expected, value)
+		}
+	}
+
+	byteSeq.Release(0x03)
+	byteSeq.Release(0x02)
+	for _, expected := range []byte{0x02, 0x03, 0x05} {
+		if value, _ := byteSeq.NextValue(); value != expected {
+			t.Errorf("Expected freed values lowest first, wanted 0x%02x, got 0x%02x", expected, value)
+		}
+	}
+}
+
+func TestStatefulOrderingsAreCopied(t *testing.T) {
+	orderings := map[string]func() Ordering{
+		"LFSR":           func() Ordering { return LFSR(1) },
+		"Multiplicative": func() Ordering { return Multiplicative(3, 0) },
+		"VanDerCorput":   VanDerCorput,
+	}
+	for name, ordering := range orderings {
+		byteSeq := NewRandomSeq(nil, WithOrdering(ordering()))
+		want, _ := NewRandomSeq(nil, WithOrdering(ordering())).NextN(10)
+
+		// Drawing from a clone leaves the original's place alone
+		byteSeq.Clone().NextN(5)
+		if got, _ := byteSeq.NextN(5); !slices.Equal(got, want[:5]) {
+			t.Errorf("%s: a clone disturbed the original, got %v, want %v", name, got, want[:5])
+		}
+
+		// A restored sequence repeats its draws
+		snapshot := byteSeq.Snapshot()
+		first, _ := byteSeq.NextN(5)
+		byteSeq.Restore(snapshot)
+		if again, _ := byteSeq.NextN(5); !slices.Equal(again, first) || !slices.Equal(first, want[5:]) {
+			t.Errorf("%s: expected %v after restoring, got %v then %v", name, want[5:], first, again)
+		}
+	}
+}
+
+func TestShardsHaveTheirOwnOrdering(t *testing.T) {
+	split := func() []*RandomByteSeq {
+		return NewRandomSeq(nil, WithSeed(3), WithOrdering(VanDerCorput())).Split(2)
+	}
+
+	want, _ := split()[1].NextN(5)
+	shards := split()
+	shards[0].NextN(5)
+	if got, _ := shards[1].NextN(5); !slices.Equal(got, want) {
+		t.Errorf("Drawing from one shard moved another's ordering, got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+// Package otelseq wraps a byteseq.RandomByteSeq so that its batch draws and
+// lease operations show up as OpenTelemetry spans, putting allocation
+// latency and exhaustion incidents into the traces of the request path
+// that caused them.
+//
+// Each operation is a span named after the method, such as byteseq.NextN,
+// carrying the sequence's name, what was asked for and how many values
+// were left afterwards. A draw that finds the sequence exhausted records
+// the error and marks its span as failed.
+//
+// It is a module of its own, so only programs that import it depend on
+// OpenTelemetry.
+package otelseq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/owenjklan/byteseq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer the spans come from.
+const ScopeName = "github.com/owenjklan/byteseq/otelseq"
+
+// Attribute keys recorded on the spans
+const (
+	NameKey      = attribute.Key("byteseq.name")
+	RequestedKey = attribute.Key("byteseq.requested")
+	DrawnKey     = attribute.Key("byteseq.drawn")
+	RemainingKey = attribute.Key("byteseq.remaining")
+	ValueKey     = attribute.Key("byteseq.value")
+	OKKey        = attribute.Key("byteseq.ok")
+	ExpiryKey    = attribute.Key("byteseq.lease_expiry")
+)
+
+// An Option configures a Seq.
+type Option func(*Seq)
+
+// WithTracerProvider sets where the spans go. By default they go to the
+// global tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *Seq) {
+		s.tracer = tp.Tracer(ScopeName)
+	}
+}
+
+// A Seq is a RandomByteSeq that traces its batch draws and lease
+// operations. It is safe for concurrent use if the sequence it wraps is.
+type Seq struct {
+	seq    *byteseq.RandomByteSeq
+	name   string
+	tracer trace.Tracer
+}
+
+// New returns a Seq tracing the use of seq. name is recorded on every
+// span, to tell the sequence apart from others.
+func New(name string, seq *byteseq.RandomByteSeq, opts ...Option) *Seq {
+	s := &Seq{seq: seq, name: name}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.tracer == nil {
+		s.tracer = otel.GetTracerProvider().Tracer(ScopeName)
+	}
+	return s
+}
+
+// Unwrap returns the sequence the Seq traces.
+func (s *Seq) Unwrap() *byteseq.RandomByteSeq {
+	return s.seq
+}
+
+// NextN draws up to n values, as RandomByteSeq.NextN does.
+func (s *Seq) NextN(ctx context.Context, n int) ([]byte, error) {
+	_, span := s.start(ctx, "byteseq.NextN", RequestedKey.Int(n))
+	values, err := s.seq.NextN(n)
+	s.end(span, err, DrawnKey.Int(len(values)))
+	return values, err
+}
+
+// Fill draws values into dst, as RandomByteSeq.Fill does.
+func (s *Seq) Fill(ctx context.Context, dst []byte) (int, error) {
+	_, span := s.start(ctx, "byteseq.Fill", RequestedKey.Int(len(dst)))
+	n, err := s.seq.Fill(dst)
+	s.end(span, err, DrawnKey.Int(n))
+	return n, err
+}
+
+// Drain draws every value that is left, as RandomByteSeq.Drain does.
+func (s *Seq) Drain(ctx context.Context) []byte {
+	_, span := s.start(ctx, "byteseq.Drain")
+	values := s.seq.Drain()
+	s.end(span, nil, DrawnKey.Int(len(values)))
+	return values
+}
+
+// Reserve draws a value tentatively, as RandomByteSeq.Reserve does.
+func (s *Seq) Reserve(ctx context.Context) (byte, error) {
+	_, span := s.start(ctx, "byteseq.Reserve")
+	value, err := s.seq.Reserve()
+	if err == nil {
+		span.SetAttributes(ValueKey.Int(int(value)))
+	}
+	s.end(span, err)
+	return value, err
+}
+
+// Confirm makes a reserved value permanent, as RandomByteSeq.Confirm does.
+func (s *Seq) Confirm(ctx context.Context, b byte) bool {
+	_, span := s.start(ctx, "byteseq.Confirm", ValueKey.Int(int(b)))
+	ok := s.seq.Confirm(b)
+	s.end(span, nil, OKKey.Bool(ok))
+	return ok
+}
+
+// Abort hands a reserved value back, as RandomByteSeq.Abort does.
+func (s *Seq) Abort(ctx context.Context, b byte) bool {
+	_, span := s.start(ctx, "byteseq.Abort", ValueKey.Int(int(b)))
+	ok := s.seq.Abort(b)
+	s.end(span, nil, OKKey.Bool(ok))
+	return ok
+}
+
+// Renew extends the lease on b, as RandomByteSeq.Renew does.
+func (s *Seq) Renew(ctx context.Context, b byte) bool {
+	_, span := s.start(ctx, "byteseq.Renew", ValueKey.Int(int(b)))
+	ok := s.seq.Renew(b)
+	attrs := []attribute.KeyValue{OKKey.Bool(ok)}
+	if expiry, leased := s.seq.LeaseExpiry(b); leased {
+		attrs = append(attrs, ExpiryKey.String(expiry.Format(time.RFC3339Nano)))
+	}
+	s.end(span, nil, attrs...)
+	return ok
+}
+
+// Release returns b to the sequence, ending any lease on it, as
+// RandomByteSeq.Release does.
+func (s *Seq) Release(ctx context.Context, b byte) bool {
+	_, span := s.start(ctx, "byteseq.Release", ValueKey.Int(int(b)))
+	ok := s.seq.Release(b)
+	s.end(span, nil, OKKey.Bool(ok))
+	return ok
+}
+
+// start begins a span for an operation on the sequence
+func (s *Seq) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, NameKey.String(s.name))
+	return s.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// end finishes a span, recording what was left and whether it failed
+func (s *Seq) end(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	span.SetAttributes(append(attrs, RemainingKey.Int(s.seq.Remaining()))...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, byteseq.ErrExhausted) {
+			span.AddEvent("exhausted")
+		}
+	}
+	span.End()
+}
@@ -0,0 +1,85 @@
+package otelseq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/owenjklan/byteseq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTraced(seq *byteseq.RandomByteSeq) (*Seq, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return New("ids", seq, WithTracerProvider(tp)), recorder
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestBatchDrawSpans(t *testing.T) {
+	seq, recorder := newTraced(byteseq.NewRangeSeq(0, 4))
+
+	_, _ = seq.NextN(context.Background(), 3)
+	_, _ = seq.NextN(context.Background(), 3)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	first, second := spans[0], spans[1]
+	if first.Name() != "byteseq.NextN" || attr(first, NameKey).AsString() != "ids" {
+		t.Errorf("Unexpected span %q for %q", first.Name(), attr(first, NameKey).AsString())
+	}
+	if attr(first, DrawnKey).AsInt64() != 3 || attr(first, RemainingKey).AsInt64() != 2 {
+		t.Errorf("Expected 3 drawn and 2 remaining on the first span")
+	}
+	if first.Status().Code == codes.Error {
+		t.Errorf("Expected the first span to succeed")
+	}
+
+	if attr(second, DrawnKey).AsInt64() != 2 || second.Status().Code != codes.Error {
+		t.Errorf("Expected a short, failed second span")
+	}
+	if events := second.Events(); len(events) == 0 || events[len(events)-1].Name != "exhausted" {
+		t.Errorf("Expected an exhausted event, got %v", events)
+	}
+}
+
+func TestLeaseSpans(t *testing.T) {
+	seq, recorder := newTraced(byteseq.NewRandomSeq(nil, byteseq.WithLeases(time.Minute)))
+	ctx := context.Background()
+
+	value, _ := seq.Reserve(ctx)
+	seq.Renew(ctx, value)
+	seq.Confirm(ctx, value)
+	seq.Release(ctx, value)
+	seq.Release(ctx, value)
+
+	spans := recorder.Ended()
+	names := []string{"byteseq.Reserve", "byteseq.Renew", "byteseq.Confirm", "byteseq.Release", "byteseq.Release"}
+	if len(spans) != len(names) {
+		t.Fatalf("Expected %d spans, got %d", len(names), len(spans))
+	}
+	for i, name := range names {
+		if spans[i].Name() != name {
+			t.Errorf("Expected span %d to be %s, got %s", i, name, spans[i].Name())
+		}
+	}
+	if !attr(spans[1], OKKey).AsBool() || attr(spans[1], ExpiryKey).AsString() == "" {
+		t.Errorf("Expected the renewal to succeed and record the new expiry")
+	}
+	if !attr(spans[3], OKKey).AsBool() || attr(spans[4], OKKey).AsBool() {
+		t.Errorf("Expected only the first release to succeed")
+	}
+}
@@ -0,0 +1,66 @@
+package byteseq
+
+// A PairSeq returns every ordered pair of byte values once each, all 65536
+// of them, in random order, for exhaustively fuzzing two-byte fields. A
+// pair is held as [2]byte{first, second}, and {0x01, 0x02} and {0x02, 0x01}
+// are different pairs. It is built on RandomUint16Seq, with the first byte
+// of the pair in the high byte, and is not safe for concurrent use.
+type PairSeq struct {
+	seq *RandomUint16Seq
+}
+
+// NewPairSeq returns a new PairSeq. consumedPairs holds optional pairs to
+// exclude from the sequence.
+func NewPairSeq(consumedPairs [][2]byte) *PairSeq {
+	return NewPairSeqFromSource(nil, consumedPairs)
+}
+
+// NewPairSeqFromSource is like NewPairSeq, but draws from src instead of
+// the package-global source.
+func NewPairSeqFromSource(src Source, consumedPairs [][2]byte) *PairSeq {
+	consumedValues := make([]uint16, len(consumedPairs))
+	for i, pair := range consumedPairs {
+		consumedValues[i] = pairValue(pair)
+	}
+	return &PairSeq{seq: NewRandomUint16SeqFromSource(src, consumedValues)}
+}
+
+// HasMore reports whether the sequence has any more pairs to return.
+func (p *PairSeq) HasMore() bool {
+	return p.seq.HasMore()
+}
+
+// Remaining returns how many pairs can still be drawn from the sequence.
+func (p *PairSeq) Remaining() int {
+	return p.seq.Remaining()
+}
+
+// NextValue returns a random pair that hasn't been returned before, or
+// ErrExhausted once every pair has been returned.
+func (p *PairSeq) NextValue() ([2]byte, error) {
+	value, err := p.seq.NextValue()
+	if err != nil {
+		return [2]byte{}, err
+	}
+	return [2]byte{byte(value >> 8), byte(value)}, nil
+}
+
+// IsConsumed reports whether pair has already been returned or excluded.
+func (p *PairSeq) IsConsumed(pair [2]byte) bool {
+	return p.seq.IsConsumed(pairValue(pair))
+}
+
+// Consume marks pair as consumed, reporting whether it was newly consumed.
+func (p *PairSeq) Consume(pair [2]byte) bool {
+	return p.seq.Consume(pairValue(pair))
+}
+
+// Release returns a consumed pair to the sequence, reporting whether it was
+// released. Pairs excluded at construction stay excluded.
+func (p *PairSeq) Release(pair [2]byte) bool {
+	return p.seq.Release(pairValue(pair))
+}
+
+func pairValue(pair [2]byte) uint16 {
+	return uint16(pair[0])<<8 | uint16(pair[1])
+}
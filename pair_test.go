@@ -0,0 +1,50 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPairSeqReturnsEveryPairOnce(t *testing.T) {
+	pairSeq := NewPairSeq([][2]byte{{0x01, 0x02}})
+	seen := make(map[[2]byte]bool, 1<<16)
+
+	for pairSeq.HasMore() {
+		pair, err := pairSeq.NextValue()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if seen[pair] {
+			t.Fatalf("Pair %v returned twice", pair)
+		}
+		seen[pair] = true
+	}
+
+	if len(seen) != 1<<16-1 {
+		t.Errorf("Expected 65535 pairs, got %d", len(seen))
+	}
+	if seen[[2]byte{0x01, 0x02}] {
+		t.Errorf("Excluded pair {0x01, 0x02} was returned")
+	}
+	if !seen[[2]byte{0x02, 0x01}] {
+		t.Errorf("Expected the reversed pair {0x02, 0x01} to be returned")
+	}
+	if _, err := pairSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
+}
+
+func TestPairSeqConsumeAndRelease(t *testing.T) {
+	pairSeq := NewPairSeq(nil)
+	pair := [2]byte{0xAB, 0xCD}
+
+	if !pairSeq.Consume(pair) || !pairSeq.IsConsumed(pair) {
+		t.Errorf("Expected %v to be consumed", pair)
+	}
+	if !pairSeq.Release(pair) || pairSeq.IsConsumed(pair) {
+		t.Errorf("Expected %v to be released", pair)
+	}
+	if got := pairSeq.Remaining(); got != 1<<16 {
+		t.Errorf("Expected every pair to be available, got %d", got)
+	}
+}
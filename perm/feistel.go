@@ -0,0 +1,55 @@
+package perm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+// feistelRounds is enough for a Feistel network with a pseudo-random round
+// function to be indistinguishable from a random permutation.
+const feistelRounds = 6
+
+// A feistel is a balanced Feistel network over values of up to 64 bits,
+// keyed through AES. Odd bit widths are rounded up to the next even width,
+// so callers must cycle walk to stay inside their domain.
+type feistel struct {
+	halfBits uint
+	block    cipher.Block
+}
+
+func newFeistel(bits uint, key []byte) (*feistel, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &feistel{halfBits: (bits + 1) / 2, block: block}, nil
+}
+
+func (f *feistel) encrypt(x uint64) uint64 {
+	mask := uint64(1)<<f.halfBits - 1
+	left, right := x>>f.halfBits, x&mask
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, left^(f.round(round, right)&mask)
+	}
+	return left<<f.halfBits | right
+}
+
+func (f *feistel) decrypt(x uint64) uint64 {
+	mask := uint64(1)<<f.halfBits - 1
+	left, right := x>>f.halfBits, x&mask
+	for round := feistelRounds - 1; round >= 0; round-- {
+		left, right = right^(f.round(round, left)&mask), left
+	}
+	return left<<f.halfBits | right
+}
+
+// round is the Feistel round function, AES applied to the round number and
+// the half being mixed.
+func (f *feistel) round(round int, half uint64) uint64 {
+	var in, out [aes.BlockSize]byte
+	in[0] = byte(round)
+	binary.BigEndian.PutUint64(in[8:], half)
+	f.block.Encrypt(out[:], in[:])
+	return binary.BigEndian.Uint64(out[:8])
+}
@@ -0,0 +1,103 @@
+// Package perm provides keyed pseudo-random permutations of 0..N-1 that
+// need only constant memory, however large N is.
+//
+// A Permutation is a format-preserving cipher: a Feistel network keyed
+// through AES, with cycle walking to handle domains whose size isn't a
+// power of two. Walking a counter through it visits every value of the
+// domain exactly once, in an order that can't be predicted without the key.
+// This is how the package gets byteseq's "each value once" guarantee for
+// domains far too large for a bitmap.
+package perm
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// ErrExhausted is returned once a Seq has emitted every value of its domain.
+var ErrExhausted = errors.New("sequence has been exhausted")
+
+// ErrEmptyDomain is returned when asked to permute a domain with no values.
+var ErrEmptyDomain = errors.New("domain is empty")
+
+// ErrTooManyBits is returned when asked for a domain wider than 64 bits.
+var ErrTooManyBits = errors.New("domain is wider than 64 bits")
+
+// KeySize is the size of key the permutations expect, in bytes. Keys of 24
+// or 32 bytes are also accepted, selecting AES-192 or AES-256.
+const KeySize = 16
+
+// A Permutation is a keyed bijection on the values [0, N). It holds no
+// mutable state, so it is safe for concurrent use.
+type Permutation struct {
+	bits  uint   // width of the domain
+	limit uint64 // N, or zero when N is 2^64
+	f     *feistel
+}
+
+// New returns a Permutation of [0, n). It returns ErrEmptyDomain if n is
+// zero, and passes on the error from crypto/aes if key isn't a valid AES
+// key.
+func New(n uint64, key []byte) (*Permutation, error) {
+	if n == 0 {
+		return nil, ErrEmptyDomain
+	}
+	p, err := NewBits(uint(bits.Len64(n-1)), key)
+	if err != nil {
+		return nil, err
+	}
+	p.limit = n
+	return p, nil
+}
+
+// NewBits returns a Permutation of [0, 2^width). width may be anything up
+// to 64, where the permutation covers every uint64.
+func NewBits(width uint, key []byte) (*Permutation, error) {
+	if width > 64 {
+		return nil, ErrTooManyBits
+	}
+	f, err := newFeistel(width, key)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Permutation{bits: width, f: f}
+	if width < 64 {
+		p.limit = 1 << width
+	}
+	return p, nil
+}
+
+// Size returns N, the number of values in the domain. A full 64-bit domain
+// reports zero, as 2^64 doesn't fit in a uint64.
+func (p *Permutation) Size() uint64 {
+	return p.limit
+}
+
+// At returns the value at index i of the permuted order. i must be in the
+// domain.
+func (p *Permutation) At(i uint64) uint64 {
+	return p.walk(i, p.f.encrypt)
+}
+
+// Index is the inverse of At, returning the index at which v appears in the
+// permuted order. v must be in the domain.
+func (p *Permutation) Index(v uint64) uint64 {
+	return p.walk(v, p.f.decrypt)
+}
+
+// walk applies step until the result lands back inside the domain. The
+// network's width is at most one bit wider than the domain's, rounded up to
+// an even width, so this takes a handful of steps at worst on average.
+func (p *Permutation) walk(x uint64, step func(uint64) uint64) uint64 {
+	for {
+		x = step(x)
+		if p.inDomain(x) {
+			return x
+		}
+	}
+}
+
+func (p *Permutation) inDomain(x uint64) bool {
+	return p.limit == 0 || x < p.limit
+}
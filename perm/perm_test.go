@@ -0,0 +1,75 @@
+package perm
+
+import (
+	"errors"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func TestEveryValueOnce(t *testing.T) {
+	for _, n := range []uint64{1, 2, 3, 7, 100, 256, 1000, 65537} {
+		seq, err := NewSeq(n, testKey)
+		if err != nil {
+			t.Fatalf("n=%d: unexpected error: %v", n, err)
+		}
+
+		seen := make([]bool, n)
+		for seq.HasMore() {
+			v, _ := seq.NextValue()
+			if v >= n || seen[v] {
+				t.Fatalf("n=%d: unexpected or repeated value %d", n, v)
+			}
+			seen[v] = true
+		}
+		if seq.Position() != n {
+			t.Errorf("n=%d: expected %d values but got %d", n, n, seq.Position())
+		}
+		if _, err := seq.NextValue(); !errors.Is(err, ErrExhausted) {
+			t.Errorf("n=%d: expected ErrExhausted but got %v", n, err)
+		}
+	}
+}
+
+func TestIndexInvertsAt(t *testing.T) {
+	p, _ := New(1_000_003, testKey)
+	for i := uint64(0); i < 1000; i++ {
+		if got := p.Index(p.At(i)); got != i {
+			t.Fatalf("Expected Index(At(%d)) to be %d but got %d", i, i, got)
+		}
+	}
+
+	full, _ := NewBits(64, testKey)
+	for _, v := range []uint64{0, 1, 1 << 63, ^uint64(0)} {
+		if got := full.At(full.Index(v)); got != v {
+			t.Errorf("Expected At(Index(%d)) to be %d but got %d", v, v, got)
+		}
+	}
+}
+
+func TestDifferentKeysGiveDifferentOrders(t *testing.T) {
+	a, _ := New(1<<20, testKey)
+	b, _ := New(1<<20, []byte("fedcba9876543210"))
+
+	same := 0
+	for i := uint64(0); i < 100; i++ {
+		if a.At(i) == b.At(i) {
+			same++
+		}
+	}
+	if same > 5 {
+		t.Errorf("Expected keys to give unrelated orders, but %d of 100 matched", same)
+	}
+}
+
+func TestInvalidDomains(t *testing.T) {
+	if _, err := New(0, testKey); !errors.Is(err, ErrEmptyDomain) {
+		t.Errorf("Expected ErrEmptyDomain but got %v", err)
+	}
+	if _, err := NewBits(65, testKey); !errors.Is(err, ErrTooManyBits) {
+		t.Errorf("Expected ErrTooManyBits but got %v", err)
+	}
+	if _, err := New(10, []byte("short")); err == nil {
+		t.Errorf("Expected an error for an invalid key")
+	}
+}
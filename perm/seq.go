@@ -0,0 +1,70 @@
+package perm
+
+// A Seq emits every value of a Permutation's domain once, by walking a
+// counter through it. Its whole state is the key and the position, so a
+// sequence can be resumed anywhere with Seek. It is not safe for concurrent
+// use.
+type Seq struct {
+	perm     *Permutation
+	position uint64
+	done     bool // only needed for full 64-bit domains, where position wraps
+}
+
+// NewSeq returns a Seq over the values [0, n), ordered by a Permutation
+// made with key.
+func NewSeq(n uint64, key []byte) (*Seq, error) {
+	p, err := New(n, key)
+	if err != nil {
+		return nil, err
+	}
+	return p.Seq(), nil
+}
+
+// Seq returns a new Seq that walks through the permutation from the start.
+func (p *Permutation) Seq() *Seq {
+	return &Seq{perm: p}
+}
+
+// HasMore reports whether any values are left to emit.
+func (s *Seq) HasMore() bool {
+	return !s.done && s.perm.inDomain(s.position)
+}
+
+// Position returns how many values have been emitted so far.
+func (s *Seq) Position() uint64 {
+	return s.position
+}
+
+// Remaining returns how many values are left to emit. For a full 64-bit
+// domain that hasn't emitted anything yet, the true answer of 2^64 doesn't
+// fit and zero is returned; use HasMore to tell the cases apart.
+func (s *Seq) Remaining() uint64 {
+	if !s.HasMore() {
+		return 0
+	}
+	return s.perm.limit - s.position
+}
+
+// Seek moves the sequence to position, so the next value emitted is the one
+// that would have followed that many earlier values. Positions past the end
+// leave the sequence exhausted.
+func (s *Seq) Seek(position uint64) {
+	s.position = position
+	s.done = false
+	if !s.perm.inDomain(position) {
+		s.position = s.perm.limit
+	}
+}
+
+// NextValue returns the next value in the permuted order, or ErrExhausted
+// once every value has been emitted.
+func (s *Seq) NextValue() (uint64, error) {
+	if !s.HasMore() {
+		return 0, ErrExhausted
+	}
+
+	v := s.perm.At(s.position)
+	s.position++
+	s.done = s.position == 0
+	return v, nil
+}
@@ -0,0 +1,70 @@
+package byteseq
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit paces the sequence's draws, so that on average no more than
+// limit values are handed out per second, with bursts of up to burst values.
+// NextValue, NextValueCtx and TryNextValue wait their turn, as does
+// everything built on them, such as Values, ForEach and Stream; NextValueCtx
+// gives up early if its context is done first. The constrained draws,
+// Reserve, the batch methods, Skip and the io methods are paced too, with a
+// batch waiting up front for as many values as it will draw, a burst at a
+// time. Peek and Consume don't draw a value, so they aren't paced. Clones
+// and shards made by Split share the limit with the original, so between
+// them they never go faster than limit.
+//
+// limit must not be negative, and burst must be at least one unless limit
+// is rate.Inf.
+func WithRateLimit(limit rate.Limit, burst int) Option {
+	return func(c *config) {
+		if limit < 0 {
+			c.fail("rate limit %v is negative", limit)
+			return
+		}
+		if burst < 1 && limit != rate.Inf {
+			c.fail("rate limit burst %d is less than one", burst)
+			return
+		}
+		c.limiter = rate.NewLimiter(limit, burst)
+	}
+}
+
+// wait blocks until the rate limit allows another draw, or ctx is done. It
+// must be called without the lock held, so a waiting draw doesn't hold up
+// the rest of the sequence.
+func (r *RandomByteSeq) wait(ctx context.Context) error {
+	if r.limiter == nil {
+		return nil
+	}
+	return r.limiter.Wait(ctx)
+}
+
+// waitN is wait for a batch of n draws. The limiter won't wait for more
+// than its burst at once, so the draws are waited for a burst at a time.
+func (r *RandomByteSeq) waitN(ctx context.Context, n int) error {
+	if r.limiter == nil || r.limiter.Limit() == rate.Inf {
+		return nil
+	}
+	for n > 0 {
+		chunk := min(n, r.limiter.Burst())
+		if err := r.limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// batchSize returns how many draws a batch asking for n values will make,
+// so it can wait for no more than that. Only under ExhaustError does a
+// batch stop short when the sequence runs out.
+func (r *RandomByteSeq) batchSize(n int) int {
+	if r.limiter == nil || r.policy != ExhaustError {
+		return n
+	}
+	return min(n, r.Remaining())
+}
@@ -0,0 +1,92 @@
+package byteseq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitPacesDraws(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithRateLimit(100, 1))
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := byteSeq.NextValue(); err != nil {
+			t.Fatalf("Unexpected error from a paced draw: %v", err)
+		}
+	}
+
+	// The first draw uses the burst, the other five wait 10ms each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected paced draws to take at least 40ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimitedDrawGivesUpOnDeadline(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithRateLimit(rate.Every(time.Hour), 1))
+	_, _ = byteSeq.NextValue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := byteSeq.NextValueCtx(ctx); err == nil {
+		t.Errorf("Expected a draw that can't be made before the deadline to fail")
+	}
+	if got := byteSeq.Remaining(); got != 255 {
+		t.Errorf("Expected the failed draw not to consume a value, but %d remain", got)
+	}
+}
+
+func TestRateLimitOptionChecksSettings(t *testing.T) {
+	if _, err := NewRandomSeqChecked(nil, WithRateLimit(-1, 1)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a negative limit, got %v", err)
+	}
+	if _, err := NewRandomSeqChecked(nil, WithRateLimit(10, 0)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a zero burst, got %v", err)
+	}
+	if _, err := NewRandomSeqChecked(nil, WithRateLimit(rate.Inf, 0)); err != nil {
+		t.Errorf("Expected an unlimited rate to need no burst, got %v", err)
+	}
+}
+
+func TestRateLimitPacesBatches(t *testing.T) {
+	// Each batch of 10 uses the burst of 5 and waits 50ms for the rest
+	batches := map[string]func(*RandomByteSeq){
+		"NextN":   func(r *RandomByteSeq) { r.NextN(10) },
+		"Fill":    func(r *RandomByteSeq) { r.Fill(make([]byte, 10)) },
+		"Skip":    func(r *RandomByteSeq) { r.Skip(10) },
+		"Drain":   func(r *RandomByteSeq) { r.Drain() },
+		"WriteTo": func(r *RandomByteSeq) { r.WriteTo(io.Discard) },
+		// A large read only waits for the values that are left
+		"Read": func(r *RandomByteSeq) { io.ReadAll(r) },
+	}
+	for name, batch := range batches {
+		byteSeq := NewRangeSeq(0, 9, WithRateLimit(100, 5))
+
+		start := time.Now()
+		batch(byteSeq)
+		if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > time.Second {
+			t.Errorf("%s: expected the batch to take about 50ms, took %v", name, elapsed)
+		}
+		if byteSeq.HasMore() {
+			t.Errorf("%s: expected the batch to draw every value", name)
+		}
+	}
+}
+
+func TestRateLimitPacesConstrainedDraws(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithRateLimit(100, 1))
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := byteSeq.NextInRange(0, 15); err != nil {
+			t.Fatalf("Unexpected error from a paced draw: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected paced draws to take at least 40ms, took %v", elapsed)
+	}
+}
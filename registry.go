@@ -0,0 +1,81 @@
+package byteseq
+
+import (
+	"slices"
+	"sync"
+)
+
+// A SeqRegistry hands out sequences by name, creating each one the first
+// time it is asked for, so separate parts of a program can share the
+// sequence for a given key, such as an ID allocator per peer, without
+// passing pointers around. A SeqRegistry is safe for concurrent use.
+type SeqRegistry struct {
+	mu      sync.Mutex
+	factory func(name string) *RandomByteSeq
+	seqs    map[string]*RandomByteSeq
+}
+
+// NewSeqRegistry returns an empty registry that creates sequences with
+// factory, which is given the name being asked for. factory is called with
+// the registry's lock held, so it must not use the registry. If factory is
+// nil, sequences are created with NewSafeRandomSeq(nil). As sequences in a
+// registry are usually shared between goroutines, a factory should
+// normally create them with WithLocking.
+func NewSeqRegistry(factory func(name string) *RandomByteSeq) *SeqRegistry {
+	if factory == nil {
+		factory = func(string) *RandomByteSeq {
+			return NewSafeRandomSeq(nil)
+		}
+	}
+	return &SeqRegistry{
+		factory: factory,
+		seqs:    make(map[string]*RandomByteSeq),
+	}
+}
+
+// Get returns the sequence registered under name, creating it with the
+// registry's factory if there isn't one yet. Every call with the same name
+// returns the same sequence, until it is deleted.
+func (g *SeqRegistry) Get(name string) *RandomByteSeq {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seq, ok := g.seqs[name]
+	if !ok {
+		seq = g.factory(name)
+		g.seqs[name] = seq
+	}
+	return seq
+}
+
+// Lookup returns the sequence registered under name, reporting false rather
+// than creating one if there isn't one.
+func (g *SeqRegistry) Lookup(name string) (*RandomByteSeq, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	seq, ok := g.seqs[name]
+	return seq, ok
+}
+
+// Delete removes the sequence registered under name, so the next Get
+// creates a fresh one. Anyone still holding the old sequence can carry on
+// using it.
+func (g *SeqRegistry) Delete(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.seqs, name)
+}
+
+// Names returns the names of the registered sequences, in sorted order.
+func (g *SeqRegistry) Names() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, 0, len(g.seqs))
+	for name := range g.seqs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
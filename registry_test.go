@@ -0,0 +1,69 @@
+package byteseq
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSeqRegistrySharesSequencesByName(t *testing.T) {
+	created := 0
+	registry := NewSeqRegistry(func(name string) *RandomByteSeq {
+		created++
+		return NewRandomSeq(nil, WithLabel(name), WithLocking())
+	})
+
+	a := registry.Get("peer-a")
+	if registry.Get("peer-a") != a {
+		t.Errorf("Expected the same sequence for the same name")
+	}
+	if registry.Get("peer-b") == a {
+		t.Errorf("Expected different sequences for different names")
+	}
+	if created != 2 {
+		t.Errorf("Expected the factory to be called twice, got %d", created)
+	}
+
+	if _, ok := registry.Lookup("peer-c"); ok {
+		t.Errorf("Expected Lookup not to create a sequence")
+	}
+	if got := registry.Names(); !slices.Equal(got, []string{"peer-a", "peer-b"}) {
+		t.Errorf("Unexpected names %v", got)
+	}
+
+	registry.Delete("peer-a")
+	if registry.Get("peer-a") == a {
+		t.Errorf("Expected a fresh sequence after Delete")
+	}
+}
+
+func TestSeqRegistryConcurrentGet(t *testing.T) {
+	registry := NewSeqRegistry(nil)
+
+	values := make(chan byte, 256)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 32; j++ {
+				value, err := registry.Get("shared").NextValue()
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+					return
+				}
+				values <- value
+			}
+		}()
+	}
+	wg.Wait()
+	close(values)
+
+	var seen [256]bool
+	for value := range values {
+		if seen[value] {
+			t.Errorf("Value 0x%02x handed out twice", value)
+		}
+		seen[value] = true
+	}
+}
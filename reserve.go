@@ -0,0 +1,75 @@
+package byteseq
+
+import (
+	"context"
+	"slices"
+)
+
+// Reserve draws a value the same way NextValue does, but only tentatively:
+// the value is held back from other draws until it is either made permanent
+// with Confirm, or handed back to the sequence with Abort. It suits
+// allocation flows that can fail after picking a value, where the value
+// needs to go back without unwinding the rest of the draws as Undo would.
+//
+// A reserved value counts as consumed for everything else, so it shows up
+// in Consumed and IsConsumed and isn't handed out again while it is held.
+// Releasing a reserved value also drops the reservation. If the sequence
+// was created with WithTransform, Reserve returns the transformed value,
+// but Confirm and Abort take the value as drawn, as Release does. Under
+// ExhaustZero, the zero handed out once the sequence is exhausted isn't a
+// value that can be held, so Reserve returns ErrExhausted instead.
+func (r *RandomByteSeq) Reserve() (byte, error) {
+	_ = r.wait(context.Background())
+
+	r.lock()
+	defer r.unlock()
+
+	zero, err := r.awaitAvailable(context.Background())
+	switch {
+	case err != nil:
+		return 0, err
+	case zero:
+		return 0, ErrExhausted
+	}
+	value := r.take()
+	r.record(value)
+	r.reserved.Add(value)
+	return r.output(value, nil)
+}
+
+// Confirm makes a value held by Reserve permanently consumed. It reports
+// false if b isn't reserved, either because it was never reserved or has
+// already been confirmed, aborted or released.
+func (r *RandomByteSeq) Confirm(b byte) bool {
+	r.lock()
+	defer r.unlock()
+
+	if !r.reserved.Contains(b) {
+		return false
+	}
+	r.reserved.Remove(b)
+	return true
+}
+
+// Abort hands a value held by Reserve back to the sequence, so it can be
+// drawn again. It reports false if b isn't reserved, in which case nothing
+// is changed; in particular, a value that has been confirmed stays
+// consumed.
+func (r *RandomByteSeq) Abort(b byte) bool {
+	r.lock()
+	defer r.unlock()
+
+	if !r.reserved.Contains(b) {
+		return false
+	}
+	r.releaseByte(b)
+	return true
+}
+
+// Reserved returns the values currently held by Reserve, in numeric order.
+func (r *RandomByteSeq) Reserved() []byte {
+	r.lock()
+	defer r.unlock()
+
+	return slices.Collect(r.reserved.All())
+}
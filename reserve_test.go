@@ -0,0 +1,101 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReserveConfirm(t *testing.T) {
+	byteSeq := NewRangeSeq(10, 12)
+
+	value, err := byteSeq.Reserve()
+	if err != nil {
+		t.Fatalf("Expected a reservation, got %v", err)
+	}
+	if !byteSeq.IsConsumed(value) {
+		t.Errorf("Expected reserved value 0x%02x to be held back", value)
+	}
+	if got := byteSeq.Reserved(); len(got) != 1 || got[0] != value {
+		t.Errorf("Expected [0x%02x] to be reserved, got %v", value, got)
+	}
+
+	if !byteSeq.Confirm(value) {
+		t.Errorf("Expected 0x%02x to be confirmed", value)
+	}
+	if byteSeq.Confirm(value) || byteSeq.Abort(value) {
+		t.Errorf("Expected a confirmed value to no longer be reserved")
+	}
+	if !byteSeq.IsConsumed(value) || byteSeq.Remaining() != 2 {
+		t.Errorf("Expected a confirmed value to stay consumed")
+	}
+	checkPool(t, byteSeq)
+}
+
+func TestReserveAbort(t *testing.T) {
+	byteSeq := NewRangeSeq(10, 12)
+	value, _ := byteSeq.Reserve()
+
+	if !byteSeq.Abort(value) {
+		t.Errorf("Expected 0x%02x to be aborted", value)
+	}
+	if byteSeq.IsConsumed(value) || byteSeq.Remaining() != 3 {
+		t.Errorf("Expected an aborted value to go back to the sequence")
+	}
+	if byteSeq.Abort(value) {
+		t.Errorf("Expected a second Abort to report false")
+	}
+	checkPool(t, byteSeq)
+
+	// Values that were never reserved are left alone
+	drawn, _ := byteSeq.NextValue()
+	if byteSeq.Abort(drawn) || !byteSeq.IsConsumed(drawn) {
+		t.Errorf("Expected Abort to leave a drawn value consumed")
+	}
+}
+
+func TestReserveReleaseDropsReservation(t *testing.T) {
+	byteSeq := NewRandomSeq(nil)
+	value, _ := byteSeq.Reserve()
+	byteSeq.Release(value)
+
+	if byteSeq.Confirm(value) {
+		t.Errorf("Expected releasing 0x%02x to drop its reservation", value)
+	}
+	if got := byteSeq.Reserved(); len(got) != 0 {
+		t.Errorf("Expected no reservations, got %v", got)
+	}
+
+	value, _ = byteSeq.Reserve()
+	byteSeq.Reset()
+	if byteSeq.Abort(value) {
+		t.Errorf("Expected Reset to drop the reservation on 0x%02x", value)
+	}
+}
+
+func TestReserveExhausted(t *testing.T) {
+	byteSeq := NewRangeSeq(0, 0)
+	value, _ := byteSeq.Reserve()
+
+	if _, err := byteSeq.Reserve(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+	byteSeq.Abort(value)
+	if again, err := byteSeq.Reserve(); err != nil || again != value {
+		t.Errorf("Expected the aborted value 0x%02x back, got 0x%02x (%v)", value, again, err)
+	}
+}
+
+func TestReserveExhaustedUnderExhaustZero(t *testing.T) {
+	byteSeq := NewRangeSeq(10, 12, WithExhaustionPolicy(ExhaustZero))
+	byteSeq.Drain()
+
+	if _, err := byteSeq.Reserve(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted rather than the zero, got %v", err)
+	}
+	if byteSeq.Abort(0) {
+		t.Errorf("Expected the excluded zero not to have been reserved")
+	}
+	if err := byteSeq.Validate(); err != nil {
+		t.Errorf("Expected the sequence to stay valid, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// Package scan schedules the probes of a network scan: every pairing of a
+// set of targets with a set of ports, each exactly once, in an order that
+// jumps about across both so no one host or port is hit in a burst.
+//
+// A Scheduler numbers the pairs and walks a counter through a keyed
+// permutation of those numbers, as ipv4perm does for addresses, so its
+// memory use doesn't grow with the size of the scan and its progress is a
+// single number. A scan that is interrupted can be resumed by creating a
+// Scheduler with the same targets, ports and key, and seeking to the saved
+// position.
+package scan
+
+import (
+	"crypto/rand"
+	"errors"
+	"sync"
+
+	"github.com/owenjklan/byteseq/perm"
+)
+
+// ErrExhausted is returned once every probe has been scheduled.
+var ErrExhausted = perm.ErrExhausted
+
+// ErrNoProbes is returned when a Scheduler is asked for with no targets or
+// no ports.
+var ErrNoProbes = errors.New("no targets or no ports to scan")
+
+// KeySize is the size of the keys used by New, in bytes.
+const KeySize = perm.KeySize
+
+// A Probe is one target and port to try.
+type Probe[T comparable] struct {
+	Target T
+	Port   uint16
+}
+
+// A Scheduler hands out the probes of a scan in pseudo-random order. It is
+// safe for concurrent use, so a pool of workers can share one.
+type Scheduler[T comparable] struct {
+	targets []T
+	ports   []uint16
+	perm    *perm.Permutation
+
+	mu  sync.Mutex
+	seq *perm.Seq
+}
+
+// New returns a Scheduler over every pairing of targets with ports.
+// Duplicate targets and ports are dropped, so each pair comes up once; the
+// order of the rest is kept, as it is part of what determines the order of
+// the probes. key must be KeySize bytes long; if it is nil, a random key is
+// chosen, giving a different order each time. New returns ErrNoProbes if
+// there are no targets or no ports.
+func New[T comparable](targets []T, ports []uint16, key []byte) (*Scheduler[T], error) {
+	targets, ports = dedupe(targets), dedupe(ports)
+	if len(targets) == 0 || len(ports) == 0 {
+		return nil, ErrNoProbes
+	}
+
+	if key == nil {
+		key = make([]byte, KeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	// At most 2^16 ports means the product fits comfortably short of
+	// 2^64 for any slice of targets that fits in memory
+	p, err := perm.New(uint64(len(targets))*uint64(len(ports)), key)
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduler[T]{targets: targets, ports: ports, perm: p, seq: p.Seq()}, nil
+}
+
+// Size returns the number of probes in the scan.
+func (s *Scheduler[T]) Size() uint64 {
+	return s.perm.Size()
+}
+
+// HasMore reports whether any probes are left to schedule.
+func (s *Scheduler[T]) HasMore() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq.HasMore()
+}
+
+// Remaining returns how many probes are left to schedule.
+func (s *Scheduler[T]) Remaining() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq.Remaining()
+}
+
+// Position returns how many probes have been scheduled so far. Together
+// with the targets, ports and key, it is all that's needed to resume a scan
+// with Seek. Probes that were handed out but hadn't finished when the scan
+// stopped need to be retried separately, or the position saved needs to be
+// that of the earliest one not finished.
+func (s *Scheduler[T]) Position() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq.Position()
+}
+
+// Progress returns the fraction of the probes that have been scheduled, from
+// 0 to 1, for progress reporting.
+func (s *Scheduler[T]) Progress() float64 {
+	return float64(s.Position()) / float64(s.Size())
+}
+
+// Seek moves the scheduler to position, so the next probe is the one that
+// would have followed that many earlier probes. Positions past the end
+// leave the scheduler exhausted.
+func (s *Scheduler[T]) Seek(position uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq.Seek(position)
+}
+
+// Next returns the next probe, or ErrExhausted once every probe has been
+// scheduled.
+func (s *Scheduler[T]) Next() (Probe[T], error) {
+	s.mu.Lock()
+	i, err := s.seq.NextValue()
+	s.mu.Unlock()
+
+	if err != nil {
+		return Probe[T]{}, err
+	}
+	return s.probe(i), nil
+}
+
+// At returns the probe at index i of the scan's order, without moving the
+// scheduler. i must be less than Size.
+func (s *Scheduler[T]) At(i uint64) Probe[T] {
+	return s.probe(s.perm.At(i))
+}
+
+// probe unpacks a pair number into its target and port
+func (s *Scheduler[T]) probe(i uint64) Probe[T] {
+	n := uint64(len(s.ports))
+	return Probe[T]{Target: s.targets[i/n], Port: s.ports[i%n]}
+}
+
+// dedupe returns values with any repeats dropped, keeping the first of each
+func dedupe[T comparable](values []T) []T {
+	seen := make(map[T]struct{}, len(values))
+	unique := make([]T, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,74 @@
+package scan
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func TestEveryProbeOnce(t *testing.T) {
+	targets := []netip.Addr{
+		netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("192.0.2.2"),
+		netip.MustParseAddr("192.0.2.3"),
+		netip.MustParseAddr("192.0.2.1"),
+	}
+	ports := []uint16{22, 80, 443, 8080, 80}
+
+	s, err := New(targets, ports, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Size() != 12 {
+		t.Errorf("Expected 12 probes once duplicates are dropped, got %d", s.Size())
+	}
+
+	seen := map[Probe[netip.Addr]]bool{}
+	for s.HasMore() {
+		probe, _ := s.Next()
+		if seen[probe] {
+			t.Errorf("Probe %v scheduled twice", probe)
+		}
+		seen[probe] = true
+	}
+	if len(seen) != 12 || s.Progress() != 1 {
+		t.Errorf("Expected all 12 probes, got %d", len(seen))
+	}
+	if _, err := s.Next(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted, got %v", err)
+	}
+}
+
+func TestResume(t *testing.T) {
+	targets := []string{"alpha", "bravo", "charlie", "delta"}
+	ports := []uint16{21, 22, 23, 25, 53}
+
+	first, _ := New(targets, ports, testKey)
+	for i := 0; i < 7; i++ {
+		_, _ = first.Next()
+	}
+
+	resumed, _ := New(targets, ports, testKey)
+	resumed.Seek(first.Position())
+	for first.HasMore() {
+		a, _ := first.Next()
+		b, _ := resumed.Next()
+		if a != b {
+			t.Fatalf("Expected resumed scan to give %v, got %v", a, b)
+		}
+	}
+	if resumed.HasMore() || resumed.Remaining() != 0 {
+		t.Errorf("Expected the resumed scan to finish with the first")
+	}
+}
+
+func TestNoProbes(t *testing.T) {
+	if _, err := New([]string{"alpha"}, nil, nil); !errors.Is(err, ErrNoProbes) {
+		t.Errorf("Expected ErrNoProbes for no ports, got %v", err)
+	}
+	if _, err := New[string](nil, []uint16{80}, nil); !errors.Is(err, ErrNoProbes) {
+		t.Errorf("Expected ErrNoProbes for no targets, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package byteseq
+
+import "github.com/owenjklan/byteseq/internal/randutil"
+
+// ShuffleSlice shuffles s in place, drawing from the random source chosen
+// by opts in the same way a sequence would, so a slice shuffled with
+// WithSeed(42) is shuffled the same way every time, and one shuffled with
+// WithCryptoRand is as unpredictable as the sequences made with it. Only
+// the random source options have any effect. Like NewRandomSeq, it panics
+// if an option is given a setting it can't use.
+func ShuffleSlice[T any](s []T, opts ...Option) {
+	cfg := newConfig(opts)
+	if cfg.err != nil {
+		panic("byteseq: " + cfg.err.Error())
+	}
+
+	for i := len(s) - 1; i > 0; i-- {
+		j := randutil.Uint64n(cfg.src, uint64(i+1))
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Perm256 returns every byte value in a random order, as a lookup table,
+// for callers who want the whole permutation at once rather than drawing
+// values one by one. The order is the one a sequence made with the same
+// options would be drawn in, so with WithSeed it matches a seeded sequence
+// drained from the start. The permutation always holds all 256 values, so
+// options that would leave some out or change them, namely exclusions,
+// filters, transforms, exhaustion policies and leases, are ignored. The
+// values are never drawn from a sequence the caller can see, so hooks,
+// watermarks, logging and middleware are ignored too. Like NewRandomSeq, it
+// panics if an option is given a setting it can't use.
+func Perm256(opts ...Option) [256]byte {
+	cfg := newConfig(opts)
+	if cfg.err != nil {
+		panic("byteseq: " + cfg.err.Error())
+	}
+	cfg.excluded = nil
+	cfg.filters = nil
+	cfg.transform = nil
+	cfg.policy = ExhaustError
+	cfg.leaseTTL = 0
+	cfg.locking = false
+	cfg.limiter = nil
+	cfg.middleware = nil
+	cfg.logger = nil
+	cfg.watermarks = nil
+	cfg.onConsume = nil
+	cfg.onExhausted = nil
+
+	var perm [256]byte
+	seq := newSeq(nil, cfg)
+	for i := range perm {
+		value, err := seq.output(seq.draw())
+		if err != nil {
+			panic("byteseq: " + err.Error())
+		}
+		perm[i] = value
+	}
+	return perm
+}
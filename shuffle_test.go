@@ -0,0 +1,84 @@
+package byteseq
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestShuffleSliceKeepsElements(t *testing.T) {
+	words := []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot"}
+	shuffled := slices.Clone(words)
+	ShuffleSlice(shuffled)
+
+	slices.Sort(shuffled)
+	sorted := slices.Clone(words)
+	slices.Sort(sorted)
+	if !slices.Equal(shuffled, sorted) {
+		t.Errorf("Expected the shuffle to keep every element, got %v", shuffled)
+	}
+}
+
+func TestShuffleSliceIsReproducibleWhenSeeded(t *testing.T) {
+	a := make([]int, 100)
+	for i := range a {
+		a[i] = i
+	}
+	b := slices.Clone(a)
+
+	ShuffleSlice(a, WithSeed(8))
+	ShuffleSlice(b, WithSeed(8))
+	if !slices.Equal(a, b) {
+		t.Errorf("Expected identically seeded shuffles to match")
+	}
+	if slices.IsSorted(a) {
+		t.Errorf("Expected the slice to be shuffled")
+	}
+}
+
+func TestPerm256(t *testing.T) {
+	perm := Perm256(WithSeed(5), WithExcluded(0x00))
+
+	var seen [256]bool
+	for _, b := range perm {
+		if seen[b] {
+			t.Fatalf("Value 0x%02x appears twice in the permutation", b)
+		}
+		seen[b] = true
+	}
+
+	// It matches a seeded sequence drained from the start
+	drained := NewRandomSeqSeeded(5, nil).Drain()
+	if !slices.Equal(perm[:], drained) {
+		t.Errorf("Expected the permutation to match the seeded sequence's order")
+	}
+}
+
+func TestPerm256IgnoresOptionsThatChangeTheValues(t *testing.T) {
+	perm := Perm256(WithSeed(5),
+		WithFilter(func(b byte) bool { return b < 0x80 }),
+		WithTransform(func(byte) byte { return 0 }),
+		WithExhaustionPolicy(ExhaustZero),
+		WithLeases(time.Nanosecond))
+
+	if perm != Perm256(WithSeed(5)) {
+		t.Errorf("Expected the same permutation as with the seed alone")
+	}
+}
+
+func TestPerm256IgnoresHooks(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	count := func() { calls++ }
+	Perm256(WithSeed(5),
+		OnConsume(func(byte, int) { count() }),
+		OnExhausted(count),
+		WithWatermark(0.5, func(int) { count() }),
+		WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("Expected no hooks or logging, got %d calls and %q", calls, buf.String())
+	}
+}
@@ -0,0 +1,48 @@
+package byteseq
+
+import "slices"
+
+// A Snapshot is a saved point in the life of a sequence, made by
+// RandomByteSeq.Snapshot and returned to with RandomByteSeq.Restore. It is
+// an in-memory checkpoint, much cheaper than going through MarshalBinary,
+// and is opaque to callers.
+type Snapshot struct {
+	state RandomByteSeq
+}
+
+// Snapshot records the current state of the sequence, including the state
+// of the random source for the math/rand/v2 generators, so a restored
+// seeded sequence repeats the draws it made after the snapshot.
+func (r *RandomByteSeq) Snapshot() *Snapshot {
+	r.lock()
+	defer r.unlock()
+
+	s := &Snapshot{state: *r}
+	s.state.src = cloneSource(r.src)
+	s.state.ordering = cloneOrdering(r.ordering)
+	s.state.mu = nil
+	s.state.wake = nil
+	s.state.history = slices.Clone(r.history)
+	s.state.leases = r.leases.clone()
+	s.state.cloneHooks()
+	return s
+}
+
+// Restore returns the sequence to the state recorded in s. A snapshot can be
+// restored any number of times, which makes it a natural fit for
+// backtracking: take a snapshot, try some values, and roll back if they
+// don't work out. Whether the sequence is safe for concurrent use is not
+// part of the snapshot, and is left as it is.
+func (r *RandomByteSeq) Restore(s *Snapshot) {
+	r.lock()
+	defer r.unlock()
+
+	mu, wake := r.mu, r.wake
+	*r = s.state
+	r.src = cloneSource(s.state.src)
+	r.ordering = cloneOrdering(s.state.ordering)
+	r.history = slices.Clone(s.state.history)
+	r.leases = s.state.leases.clone()
+	r.cloneHooks()
+	r.mu, r.wake = mu, wake
+}
@@ -0,0 +1,40 @@
+package byteseq
+
+import (
+	"testing"
+)
+
+func TestRestoreRollsBackDraws(t *testing.T) {
+	byteSeq := NewRandomSeqSeeded(3, nil)
+	for i := 0; i < 10; i++ {
+		_, _ = byteSeq.NextValue()
+	}
+
+	checkpoint := byteSeq.Snapshot()
+	first, _ := byteSeq.NextN(20)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		byteSeq.Restore(checkpoint)
+		if byteSeq.Remaining() != 246 {
+			t.Fatalf("Expected 246 values after restore but got %d", byteSeq.Remaining())
+		}
+		checkPool(t, byteSeq)
+
+		again, _ := byteSeq.NextN(20)
+		for i := range first {
+			if first[i] != again[i] {
+				t.Fatalf("Expected draw %d after restore to be 0x%02x but got 0x%02x", i, first[i], again[i])
+			}
+		}
+	}
+}
+
+func TestRestoreKeepsLocking(t *testing.T) {
+	byteSeq := NewSafeRandomSeq(nil)
+	checkpoint := NewRandomSeq(nil).Snapshot()
+
+	byteSeq.Restore(checkpoint)
+	if byteSeq.mu == nil {
+		t.Errorf("Expected a safe sequence to stay safe after Restore")
+	}
+}
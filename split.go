@@ -0,0 +1,183 @@
+package byteseq
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// Split deals the values remaining in the sequence out into n new
+// sequences, at random, so no value appears in more than one of them. Each
+// shard can then be handed to its own worker with no synchronisation at
+// all, while between them they still cover every value exactly once. The
+// shard sizes differ by at most one. The receiver is drained in the
+// process, so it has no values left afterwards.
+//
+// The values outside a shard's share are treated as excluded, so Reset and
+// Release never bring them into it. Each shard gets its own random source,
+// seeded from the receiver's when it has one, so a seeded sequence splits
+// the same way every time and the shards' draws are reproducible too.
+// Options such as WithLocking and WithWeights carry over to the shards.
+// Split panics if n is less than one.
+func (r *RandomByteSeq) Split(n int) []*RandomByteSeq {
+	if n < 1 {
+		panic("byteseq: Split needs at least one shard")
+	}
+
+	r.lock()
+	defer r.unlock()
+
+	shards := make([]*RandomByteSeq, n)
+	for i := range shards {
+		shards[i] = r.emptyShard()
+	}
+	for i := 0; r.remainingValues > 0; i++ {
+		shards[i%n].releaseByte(r.take())
+	}
+	for _, shard := range shards {
+		shard.excludedBytes = shard.consumedBytes
+		shard.excludedRemaining = shard.remainingValues
+		// Only now, so the dealing isn't logged as releases
+		shard.logger = r.logger
+	}
+	return shards
+}
+
+// emptyShard returns a sequence with every value consumed, and the
+// receiver's settings, ready to be given its share by Split.
+func (r *RandomByteSeq) emptyShard() *RandomByteSeq {
+	shard := &RandomByteSeq{
+		src:         r.splitSource(),
+		weights:     r.weights,
+		limiter:     r.limiter,
+		keepHistory: r.keepHistory,
+		policy:      r.policy,
+		transform:   r.transform,
+		ordering:    cloneOrdering(r.ordering),
+		middleware:  r.middleware,
+	}
+	if r.leases != nil {
+		shard.leases = newLeaseState(r.leases.ttl, r.leases.clock)
+	}
+	if r.mu != nil {
+		shard.mu = &sync.Mutex{}
+	}
+	for i := 0; i < 256; i++ {
+		shard.consumedBytes.Add(byte(i))
+	}
+	shard.rebuildPool()
+	return shard
+}
+
+// splitSource returns a source for a new shard that doesn't share state
+// with the receiver's. The package-global source and CryptoSource are safe
+// to share already.
+func (r *RandomByteSeq) splitSource() Source {
+	switch r.src.(type) {
+	case nil, CryptoSource:
+		return r.src
+	}
+	return rand.NewPCG(r.src.Uint64(), r.src.Uint64())
+}
+
+// A ShardGroup hands out the values of a split sequence to a fixed set of
+// workers, as Split does, but lets a worker whose shard has run dry steal
+// values from its siblings. Without it, a slow worker can be left holding
+// values long after the others have finished. Each worker draws from its
+// own shard, so they only contend with each other when stealing.
+type ShardGroup struct {
+	shards []*RandomByteSeq
+	steal  sync.Mutex // one steal at a time, so shards are never locked in pairs
+}
+
+// NewShardGroup splits seq into n shards, as Split does, and returns a
+// group managing them. The shards are always made safe for concurrent use,
+// as stealing changes a shard from outside its own worker. NewShardGroup
+// panics if n is less than one.
+func NewShardGroup(seq *RandomByteSeq, n int) *ShardGroup {
+	shards := seq.Split(n)
+	for _, shard := range shards {
+		if shard.mu == nil {
+			shard.mu = &sync.Mutex{}
+		}
+	}
+	return &ShardGroup{shards: shards}
+}
+
+// Len returns the number of shards in the group.
+func (g *ShardGroup) Len() int {
+	return len(g.shards)
+}
+
+// Shard returns the i'th shard. Drawing from it directly never steals;
+// use the group's NextValue for that.
+func (g *ShardGroup) Shard(i int) *RandomByteSeq {
+	return g.shards[i]
+}
+
+// NextValue draws a value from the i'th shard. If the shard is exhausted,
+// it steals half of the values left in the fullest sibling and tries again,
+// returning ErrExhausted only once every shard in the group is empty.
+func (g *ShardGroup) NextValue(i int) (byte, error) {
+	for {
+		if value, ok := g.shards[i].TryNextValue(); ok {
+			return value, nil
+		}
+		if g.Steal(i) == 0 {
+			return 0, ErrExhausted
+		}
+	}
+}
+
+// Steal moves half of the values left in the fullest of the i'th shard's
+// siblings over to it, rounding up, and reports how many were moved. The
+// values become part of the i'th shard for good, so a later Reset of either
+// shard reflects the move.
+func (g *ShardGroup) Steal(i int) int {
+	g.steal.Lock()
+	defer g.steal.Unlock()
+
+	victim := -1
+	most := 0
+	for j, shard := range g.shards {
+		if remaining := shard.Remaining(); j != i && remaining > most {
+			victim, most = j, remaining
+		}
+	}
+	if victim < 0 {
+		return 0
+	}
+
+	values := g.shards[victim].giveAway((most + 1) / 2)
+	g.shards[i].takeOver(values)
+	return len(values)
+}
+
+// giveAway removes up to n random values from the sequence for good, as
+// though they had been excluded when it was created.
+func (r *RandomByteSeq) giveAway(n int) []byte {
+	r.lock()
+	defer r.unlock()
+
+	values := make([]byte, 0, min(n, r.remainingValues))
+	for len(values) < n && r.remainingValues > 0 {
+		value := r.take()
+		r.excludedBytes.Add(value)
+		r.excludedRemaining--
+		values = append(values, value)
+	}
+	return values
+}
+
+// takeOver makes values part of the sequence, undoing their exclusion.
+// None of them may already be available.
+func (r *RandomByteSeq) takeOver(values []byte) {
+	r.lock()
+	defer r.unlock()
+
+	for _, value := range values {
+		r.excludedBytes.Remove(value)
+		r.excludedRemaining++
+		r.returnByte(value)
+		r.debugValue("value transferred", value)
+	}
+}
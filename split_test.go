@@ -0,0 +1,129 @@
+package byteseq
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitCoversRemainingValuesOnce(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x00, 0x01, 0x02}, WithSeed(3))
+	shards := byteSeq.Split(5)
+
+	if byteSeq.HasMore() {
+		t.Errorf("Expected the split sequence to be drained, but %d values remain", byteSeq.Remaining())
+	}
+
+	seen := make(map[byte]bool)
+	for i, shard := range shards {
+		if got := shard.Remaining(); got != 50 && got != 51 {
+			t.Errorf("Shard %d has %d values, expected 50 or 51", i, got)
+		}
+		for _, value := range shard.Drain() {
+			if seen[value] {
+				t.Errorf("Value 0x%02x appears in more than one shard", value)
+			}
+			seen[value] = true
+		}
+	}
+
+	if len(seen) != 253 {
+		t.Errorf("Expected the shards to cover 253 values, got %d", len(seen))
+	}
+	for _, b := range []byte{0x00, 0x01, 0x02} {
+		if seen[b] {
+			t.Errorf("Consumed value 0x%02x was handed to a shard", b)
+		}
+	}
+}
+
+func TestSplitShardsKeepToTheirShare(t *testing.T) {
+	shards := NewRandomSeq(nil).Split(2)
+	share := shards[0].Unconsumed()
+
+	shards[0].Drain()
+	shards[0].Reset()
+	if got := shards[0].Unconsumed(); !slices.Equal(got, share) {
+		t.Errorf("Expected Reset to restore the shard's own share of %d values, got %d", len(share), len(got))
+	}
+
+	other := shards[1].Unconsumed()
+	if shards[0].Release(other[0]) {
+		t.Errorf("Expected Release of another shard's value 0x%02x to be refused", other[0])
+	}
+}
+
+func TestSplitIsReproducibleWhenSeeded(t *testing.T) {
+	first := NewRandomSeqSeeded(11, nil).Split(3)
+	second := NewRandomSeqSeeded(11, nil).Split(3)
+
+	for i := range first {
+		a, b := first[i].Drain(), second[i].Drain()
+		if !slices.Equal(a, b) {
+			t.Errorf("Shard %d differs between identically seeded splits", i)
+		}
+	}
+}
+
+func TestShardGroupStealsFromSiblings(t *testing.T) {
+	group := NewShardGroup(NewRandomSeq([]byte{0xFF}), 4)
+
+	// A single worker should end up with everything by stealing
+	seen := make(map[byte]bool)
+	for {
+		value, err := group.NextValue(0)
+		if err != nil {
+			break
+		}
+		if seen[value] {
+			t.Fatalf("Value 0x%02x was handed out twice", value)
+		}
+		seen[value] = true
+	}
+
+	if len(seen) != 255 {
+		t.Errorf("Expected the worker to get all 255 values, got %d", len(seen))
+	}
+	for i := 0; i < group.Len(); i++ {
+		if group.Shard(i).HasMore() {
+			t.Errorf("Expected shard %d to be empty", i)
+		}
+	}
+
+	// The stolen values now belong to the worker's shard
+	group.Shard(0).Reset()
+	if got := group.Shard(0).Remaining(); got != 255 {
+		t.Errorf("Expected Reset to give shard 0 all 255 values, got %d", got)
+	}
+}
+
+func TestShardGroupConcurrentWorkers(t *testing.T) {
+	group := NewShardGroup(NewRandomSeq(nil), 8)
+
+	results := make(chan []byte, group.Len())
+	for i := 0; i < group.Len(); i++ {
+		go func() {
+			var values []byte
+			for {
+				value, err := group.NextValue(i)
+				if err != nil {
+					break
+				}
+				values = append(values, value)
+			}
+			results <- values
+		}()
+	}
+
+	seen := make(map[byte]bool)
+	for i := 0; i < group.Len(); i++ {
+		for _, value := range <-results {
+			if seen[value] {
+				t.Errorf("Value 0x%02x was handed out twice", value)
+			}
+			seen[value] = true
+		}
+	}
+	if len(seen) != 256 {
+		t.Errorf("Expected all 256 values between the workers, got %d", len(seen))
+	}
+}
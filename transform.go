@@ -0,0 +1,30 @@
+package byteseq
+
+// WithTransform passes every value the sequence hands out through fn on the
+// way out, for mapping the sequence into some other encoding without
+// wrapping every call site. It applies to every way of drawing a value,
+// including Peek, Stream and the io.Reader methods. Everything else works
+// with the values as drawn, before the transform: Consume, Release,
+// IsConsumed, the history and so on.
+//
+// The sequence only promises that the values it draws are unique. If fn
+// maps two values to the same result, the transformed values will repeat;
+// use a one-to-one fn to keep them unique.
+func WithTransform(fn func(byte) byte) Option {
+	return func(c *config) {
+		if fn == nil {
+			c.fail("transform is nil")
+			return
+		}
+		c.transform = fn
+	}
+}
+
+// output applies the transform set by WithTransform to a value on its way
+// out of the sequence, passing errors through untouched.
+func (r *RandomByteSeq) output(b byte, err error) (byte, error) {
+	if err != nil || r.transform == nil {
+		return b, err
+	}
+	return r.transform(b), nil
+}
@@ -0,0 +1,90 @@
+package byteseq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTransformAppliesToOutput(t *testing.T) {
+	invert := func(b byte) byte { return ^b }
+	byteSeq := NewRangeSeq(0x00, 0x0F, WithTransform(invert))
+
+	peeked, _ := byteSeq.Peek()
+	value, _ := byteSeq.NextValue()
+	if value != peeked {
+		t.Errorf("Expected Peek to report the transformed value 0x%02x, got 0x%02x", value, peeked)
+	}
+	if value < 0xF0 {
+		t.Errorf("Expected a transformed value of at least 0xF0, got 0x%02x", value)
+	}
+
+	// Everything else works with the values as drawn
+	if !byteSeq.IsConsumed(^value) {
+		t.Errorf("Expected the untransformed value 0x%02x to be consumed", ^value)
+	}
+
+	for _, value := range byteSeq.Drain() {
+		if value < 0xF0 {
+			t.Errorf("Drain returned untransformed value 0x%02x", value)
+		}
+	}
+}
+
+func TestTransformedWriteToReleasesDrawnValues(t *testing.T) {
+	byteSeq := NewRangeSeq(0x00, 0x0F, WithTransform(func(b byte) byte { return b + 0x40 }))
+
+	w := &capturingWriter{limit: 4}
+	if _, err := byteSeq.WriteTo(w); err == nil {
+		t.Fatalf("Expected a short write to report an error")
+	}
+	for _, b := range w.buf.Bytes() {
+		if b < 0x40 || b > 0x4F {
+			t.Errorf("Expected transformed values to be written, got 0x%02x", b)
+		}
+	}
+	if got := byteSeq.Remaining(); got != 12 {
+		t.Errorf("Expected the 12 unwritten values to be released, got %d remaining", got)
+	}
+	checkPool(t, byteSeq)
+}
+
+func TestTransformedStreamReleasesDrawnValues(t *testing.T) {
+	byteSeq := NewRandomSeq(nil, WithLocking(), WithTransform(func(byte) byte { return 0 }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := byteSeq.Stream(ctx, 0)
+	<-ch
+	cancel()
+	received := 1
+	for range ch {
+		received++
+	}
+
+	if got := byteSeq.Remaining(); got != 256-received {
+		t.Errorf("Expected only the %d received values to be consumed, got %d remaining", received, got)
+	}
+	checkPool(t, byteSeq)
+}
+
+func TestTransformMustNotBeNil(t *testing.T) {
+	if _, err := NewRandomSeqChecked(nil, WithTransform(nil)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a nil transform, got %v", err)
+	}
+}
+
+// capturingWriter keeps up to limit bytes, and fails writes beyond that
+type capturingWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *capturingWriter) Write(p []byte) (int, error) {
+	n := min(len(p), w.limit-w.buf.Len())
+	w.buf.Write(p[:n])
+	if n < len(p) {
+		return n, errors.New("writer is full")
+	}
+	return n, nil
+}
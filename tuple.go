@@ -0,0 +1,113 @@
+package byteseq
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/owenjklan/byteseq/perm"
+)
+
+// MaxTupleSize is the longest tuple a TupleSeq can return. The number of
+// 8-tuples of distinct byte values only just fits in a uint64.
+const MaxTupleSize = 8
+
+// ErrInvalidTupleSize is returned by NewTupleSeq when asked for tuples of
+// fewer than one or more than MaxTupleSize values.
+var ErrInvalidTupleSize = errors.New("tuple size must be from 1 to 8")
+
+// A TupleSeq returns every k-tuple of distinct byte values once each, in
+// pseudo-random order, for generating combinatorial test input. Order
+// matters, so {1, 2} and {2, 1} are different tuples, and no tuple repeats
+// a value. There are 256!/(256-k)! of them, which is 65280 for k=2 and
+// about 1.65e19 for k=8.
+//
+// Rather than tracking which tuples have been returned, it walks a counter
+// through a keyed permutation from package perm and decodes each index into
+// a tuple, so it takes the same small amount of memory whatever k is. The
+// same key always gives the same order. It is not safe for concurrent use.
+type TupleSeq struct {
+	k    int
+	size uint64
+	seq  *perm.Seq
+}
+
+// NewTupleSeq returns a TupleSeq over tuples of k values. key must be
+// perm.KeySize bytes long; if it is nil, a random key is chosen, giving a
+// different order each time. It returns ErrInvalidTupleSize if k is out of
+// range.
+func NewTupleSeq(k int, key []byte) (*TupleSeq, error) {
+	if k < 1 || k > MaxTupleSize {
+		return nil, ErrInvalidTupleSize
+	}
+
+	key, err := keyOrRandom(key)
+	if err != nil {
+		return nil, err
+	}
+
+	size := uint64(1)
+	for i := 0; i < k; i++ {
+		size *= uint64(256 - i)
+	}
+	seq, err := perm.NewSeq(size, key)
+	if err != nil {
+		return nil, err
+	}
+	return &TupleSeq{k: k, size: size, seq: seq}, nil
+}
+
+// keyOrRandom returns key, or a random key for package perm if it is nil
+func keyOrRandom(key []byte) ([]byte, error) {
+	if key != nil {
+		return key, nil
+	}
+	key = make([]byte, perm.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Size returns how many tuples the sequence covers in total.
+func (t *TupleSeq) Size() uint64 {
+	return t.size
+}
+
+// HasMore reports whether the sequence has any more tuples to return.
+func (t *TupleSeq) HasMore() bool {
+	return t.seq.HasMore()
+}
+
+// Remaining returns how many tuples can still be drawn from the sequence.
+func (t *TupleSeq) Remaining() uint64 {
+	return t.seq.Remaining()
+}
+
+// NextValue returns a tuple that hasn't been returned before, as a new
+// slice of k values, or ErrExhausted once every tuple has been returned.
+func (t *TupleSeq) NextValue() ([]byte, error) {
+	index, err := t.seq.NextValue()
+	if err != nil {
+		return nil, ErrExhausted
+	}
+	return t.tuple(index), nil
+}
+
+// tuple decodes an index into the tuple it stands for. The index is read as
+// a mixed-radix number whose i'th digit, from 0 to 255-i, picks among the
+// values not already in the tuple.
+func (t *TupleSeq) tuple(index uint64) []byte {
+	var unused ByteSet
+	for i := range unused {
+		unused[i] = 0xFF
+	}
+
+	tuple := make([]byte, t.k)
+	for i := range tuple {
+		radix := uint64(256 - i)
+		tuple[i] = unused.nth(int(index % radix))
+		unused.Remove(tuple[i])
+		index /= radix
+	}
+	return tuple
+}
@@ -0,0 +1,71 @@
+package byteseq
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef")
+
+func TestTupleSeqCoversEveryTupleOnce(t *testing.T) {
+	tupleSeq, err := NewTupleSeq(2, testKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := tupleSeq.Size(); got != 256*255 {
+		t.Errorf("Expected 65280 tuples, got %d", got)
+	}
+
+	seen := make(map[[2]byte]bool)
+	for tupleSeq.HasMore() {
+		tuple, _ := tupleSeq.NextValue()
+		if tuple[0] == tuple[1] {
+			t.Fatalf("Tuple %v repeats a value", tuple)
+		}
+		key := [2]byte{tuple[0], tuple[1]}
+		if seen[key] {
+			t.Fatalf("Tuple %v returned twice", tuple)
+		}
+		seen[key] = true
+	}
+	if len(seen) != 256*255 {
+		t.Errorf("Expected 65280 distinct tuples, got %d", len(seen))
+	}
+	if _, err := tupleSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
+}
+
+func TestTupleSeqLongTuples(t *testing.T) {
+	tupleSeq, err := NewTupleSeq(MaxTupleSize, testKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := tupleSeq.Size(); got != 16517640193528320000 {
+		t.Errorf("Unexpected number of 8-tuples: %d", got)
+	}
+
+	again, _ := NewTupleSeq(MaxTupleSize, testKey)
+	for i := 0; i < 100; i++ {
+		tuple, _ := tupleSeq.NextValue()
+		var values ByteSet
+		for _, b := range tuple {
+			values.Add(b)
+		}
+		if values.Count() != MaxTupleSize {
+			t.Errorf("Tuple %v repeats a value", tuple)
+		}
+		if other, _ := again.NextValue(); !slices.Equal(tuple, other) {
+			t.Errorf("Expected the same key to give the same order, got %v and %v", tuple, other)
+		}
+	}
+}
+
+func TestTupleSeqRejectsBadSizes(t *testing.T) {
+	for _, k := range []int{0, -1, MaxTupleSize + 1} {
+		if _, err := NewTupleSeq(k, nil); !errors.Is(err, ErrInvalidTupleSize) {
+			t.Errorf("Expected ErrInvalidTupleSize for k=%d, got %v", k, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package byteseq
+
+import (
+	"math"
+
+	"github.com/owenjklan/byteseq/uniq"
+)
+
+// A RandomUint16Seq is the 16-bit big brother of RandomByteSeq. It returns
+// every value from 0 to 65535 once each, in random order, which is the
+// classic way of ordering ports for a scan. It is not safe for concurrent
+// use.
+type RandomUint16Seq struct {
+	seq *uniq.RandomSeq[uint16]
+}
+
+// NewRandomUint16Seq returns a new RandomUint16Seq. As with NewRandomSeq,
+// consumedValues holds optional values to exclude from the sequence.
+func NewRandomUint16Seq(consumedValues []uint16) *RandomUint16Seq {
+	return NewRandomUint16SeqFromSource(nil, consumedValues)
+}
+
+// NewRandomUint16SeqFromSource is like NewRandomUint16Seq, but draws from src
+// instead of the package-global source.
+func NewRandomUint16SeqFromSource(src Source, consumedValues []uint16) *RandomUint16Seq {
+	// The full uint16 range is always valid
+	seq, _ := uniq.NewFromSource(src, 0, math.MaxUint16, consumedValues)
+	return &RandomUint16Seq{seq: seq}
+}
+
+// HasMore reports whether the sequence has any more values to return.
+func (r *RandomUint16Seq) HasMore() bool {
+	return r.seq.HasMore()
+}
+
+// Remaining returns how many values can still be drawn from the sequence.
+func (r *RandomUint16Seq) Remaining() int {
+	return int(r.seq.Remaining())
+}
+
+// NextValue returns a random value that hasn't been returned before, or
+// ErrExhausted once every value has been returned.
+func (r *RandomUint16Seq) NextValue() (uint16, error) {
+	value, err := r.seq.NextValue()
+	if err != nil {
+		return 0, ErrExhausted
+	}
+	return value, nil
+}
+
+// IsConsumed reports whether v has already been returned or excluded.
+func (r *RandomUint16Seq) IsConsumed(v uint16) bool {
+	return r.seq.IsConsumed(v)
+}
+
+// Consume marks v as consumed, reporting whether it was newly consumed.
+func (r *RandomUint16Seq) Consume(v uint16) bool {
+	return r.seq.Consume(v)
+}
+
+// Release returns a consumed value to the sequence, reporting whether it was
+// released. Values excluded at construction stay excluded.
+func (r *RandomUint16Seq) Release(v uint16) bool {
+	return r.seq.Release(v)
+}
@@ -0,0 +1,30 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUint16SequenceReturnsEveryPortOnce(t *testing.T) {
+	portSeq := NewRandomUint16Seq([]uint16{0})
+	seen := make([]int, 1<<16)
+
+	for portSeq.HasMore() {
+		port, _ := portSeq.NextValue()
+		seen[port]++
+	}
+
+	for port, count := range seen {
+		expected := 1
+		if port == 0 {
+			expected = 0
+		}
+		if count != expected {
+			t.Fatalf("Expected %d occurrences of port %d but got %d", expected, port, count)
+		}
+	}
+
+	if _, err := portSeq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
+}
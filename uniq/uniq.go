@@ -0,0 +1,203 @@
+// Package uniq generalises byteseq's random, each-value-once sequence to
+// any integer type, over any inclusive range of values.
+//
+// Rather than keeping every value of the domain in memory, a RandomSeq runs
+// a "virtual" Fisher-Yates shuffle: only the positions that have been
+// disturbed by a draw are remembered, so memory use grows with the number
+// of values drawn rather than the size of the domain. A sequence over all
+// of uint32 is as cheap to create as one over ten values.
+package uniq
+
+import (
+	"errors"
+	"math"
+
+	"github.com/owenjklan/byteseq/internal/randutil"
+)
+
+// ErrExhausted is returned when a value is requested from a sequence that
+// has none left.
+var ErrExhausted = errors.New("sequence has been exhausted")
+
+// ErrInvalidRange is returned when a range's low end is above its high end,
+// or the range holds more values than can be counted in a uint64.
+var ErrInvalidRange = errors.New("invalid range")
+
+// Integer is the set of types a RandomSeq can be made over.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// A Source is the minimal random number generator a RandomSeq needs. It is
+// the same as rand.Source from math/rand/v2 and byteseq.Source.
+type Source = randutil.Source
+
+// A RandomSeq returns the values of the range it was created over in a
+// random order, each value only once. It is not safe for concurrent use.
+type RandomSeq[T Integer] struct {
+	lo        T
+	size      uint64 // number of values in the range
+	remaining uint64
+	src       Source // nil means the package-global source
+
+	// Values are handled as offsets from lo. Slots [0, remaining) hold the
+	// unconsumed offsets. Each map only records entries that differ from
+	// the identity layout, where slot i holds offset i.
+	slots     map[uint64]uint64 // slot -> offset
+	positions map[uint64]uint64 // offset -> slot
+	excluded  map[uint64]struct{}
+}
+
+// New returns a RandomSeq over the inclusive range [lo, hi], leaving out any
+// values given in excluded. Values in excluded that fall outside the range
+// are ignored. It returns ErrInvalidRange if lo is greater than hi, or if
+// the range covers every value of a 64-bit type.
+func New[T Integer](lo, hi T, excluded []T) (*RandomSeq[T], error) {
+	return NewFromSource(nil, lo, hi, excluded)
+}
+
+// NewFromSource is like New, but draws from src instead of the
+// package-global source. A nil src selects the package-global source.
+func NewFromSource[T Integer](src Source, lo, hi T, excluded []T) (*RandomSeq[T], error) {
+	if lo > hi {
+		return nil, ErrInvalidRange
+	}
+
+	// Offsets are worked out in uint64, where two's complement wrapping
+	// gives the right answer for signed types too.
+	span := uint64(hi) - uint64(lo)
+	if span == math.MaxUint64 {
+		return nil, ErrInvalidRange
+	}
+
+	seq := &RandomSeq[T]{
+		lo:        lo,
+		size:      span + 1,
+		remaining: span + 1,
+		src:       src,
+		slots:     make(map[uint64]uint64),
+		positions: make(map[uint64]uint64),
+		excluded:  make(map[uint64]struct{}),
+	}
+	for _, v := range excluded {
+		if seq.Consume(v) {
+			seq.excluded[seq.offset(v)] = struct{}{}
+		}
+	}
+	return seq, nil
+}
+
+// HasMore reports whether the sequence has any more values to return.
+func (s *RandomSeq[T]) HasMore() bool {
+	return s.remaining > 0
+}
+
+// Remaining returns how many values are left in the sequence.
+func (s *RandomSeq[T]) Remaining() uint64 {
+	return s.remaining
+}
+
+// Size returns how many values the range covers in total.
+func (s *RandomSeq[T]) Size() uint64 {
+	return s.size
+}
+
+// NextValue returns a random value from the range that hasn't been returned
+// before, or ErrExhausted once there are none left.
+func (s *RandomSeq[T]) NextValue() (T, error) {
+	if s.remaining == 0 {
+		return 0, ErrExhausted
+	}
+
+	slot := randutil.Uint64n(s.src, s.remaining)
+	off := s.slot(slot)
+	s.remaining--
+	s.swap(slot, s.remaining)
+	return s.value(off), nil
+}
+
+// IsConsumed reports whether v has been drawn or excluded. Values outside
+// the range always report true, as they can never be returned.
+func (s *RandomSeq[T]) IsConsumed(v T) bool {
+	if !s.inRange(v) {
+		return true
+	}
+	return s.position(s.offset(v)) >= s.remaining
+}
+
+// Consume marks v as consumed so it is never returned, reporting whether it
+// was newly consumed. Values outside the range report false.
+func (s *RandomSeq[T]) Consume(v T) bool {
+	if s.IsConsumed(v) {
+		return false
+	}
+
+	s.remaining--
+	s.swap(s.position(s.offset(v)), s.remaining)
+	return true
+}
+
+// Release returns a consumed value to the sequence so it can be drawn again,
+// reporting whether it was released. Values that were excluded when the
+// sequence was created, and values outside the range, stay out.
+func (s *RandomSeq[T]) Release(v T) bool {
+	if !s.inRange(v) || !s.IsConsumed(v) {
+		return false
+	}
+	off := s.offset(v)
+	if _, ok := s.excluded[off]; ok {
+		return false
+	}
+
+	s.swap(s.position(off), s.remaining)
+	s.remaining++
+	return true
+}
+
+func (s *RandomSeq[T]) inRange(v T) bool {
+	return v >= s.lo && s.offset(v) < s.size
+}
+
+func (s *RandomSeq[T]) offset(v T) uint64 {
+	return uint64(v) - uint64(s.lo)
+}
+
+func (s *RandomSeq[T]) value(off uint64) T {
+	return T(uint64(s.lo) + off)
+}
+
+// slot returns the offset held in slot i
+func (s *RandomSeq[T]) slot(i uint64) uint64 {
+	if off, ok := s.slots[i]; ok {
+		return off
+	}
+	return i
+}
+
+// position returns the slot holding offset off
+func (s *RandomSeq[T]) position(off uint64) uint64 {
+	if i, ok := s.positions[off]; ok {
+		return i
+	}
+	return off
+}
+
+// swap exchanges the contents of slots i and j
+func (s *RandomSeq[T]) swap(i, j uint64) {
+	a, b := s.slot(i), s.slot(j)
+	s.set(i, b)
+	s.set(j, a)
+}
+
+// set puts offset off into slot i, forgetting entries that are back to
+// the identity layout so the maps stay small.
+func (s *RandomSeq[T]) set(i, off uint64) {
+	if i == off {
+		delete(s.slots, i)
+		delete(s.positions, off)
+		return
+	}
+	s.slots[i] = off
+	s.positions[off] = i
+}
@@ -0,0 +1,87 @@
+package uniq
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestUint16SequenceCoversRange(t *testing.T) {
+	seq, err := New[uint16](0, math.MaxUint16, []uint16{80, 443})
+	if err != nil {
+		t.Fatalf("Unexpected error creating sequence: %v", err)
+	}
+
+	seen := make([]bool, 1<<16)
+	count := 0
+	for seq.HasMore() {
+		value, _ := seq.NextValue()
+		if seen[value] {
+			t.Fatalf("Value %d returned twice", value)
+		}
+		seen[value] = true
+		count++
+	}
+
+	if count != 1<<16-2 || seen[80] || seen[443] {
+		t.Errorf("Expected every value except the exclusions, got %d values", count)
+	}
+	if _, err := seq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
+}
+
+func TestSignedRange(t *testing.T) {
+	seq, err := New[int8](-5, 5, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating sequence: %v", err)
+	}
+	if seq.Size() != 11 {
+		t.Errorf("Expected a range of 11 values but got %d", seq.Size())
+	}
+
+	seen := map[int8]bool{}
+	for seq.HasMore() {
+		value, _ := seq.NextValue()
+		if value < -5 || value > 5 || seen[value] {
+			t.Errorf("Unexpected value %d", value)
+		}
+		seen[value] = true
+	}
+}
+
+func TestConsumeAndRelease(t *testing.T) {
+	seq, _ := New(100, 199, []int{150})
+
+	if !seq.Consume(120) || seq.Consume(120) || seq.Consume(500) {
+		t.Errorf("Expected only the first Consume of an in-range value to succeed")
+	}
+	if seq.Remaining() != 98 {
+		t.Errorf("Expected 98 values remaining but got %d", seq.Remaining())
+	}
+	if seq.Release(150) {
+		t.Errorf("Expected excluded value to stay excluded")
+	}
+	if !seq.Release(120) || seq.Release(120) {
+		t.Errorf("Expected only the first Release of 120 to succeed")
+	}
+
+	for seq.HasMore() {
+		value, _ := seq.NextValue()
+		if value == 150 {
+			t.Errorf("Excluded value was returned")
+		}
+	}
+}
+
+func TestInvalidRanges(t *testing.T) {
+	if _, err := New(10, 5, nil); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Expected ErrInvalidRange for lo > hi but got %v", err)
+	}
+	if _, err := New[uint64](0, math.MaxUint64, nil); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Expected ErrInvalidRange for the full uint64 range but got %v", err)
+	}
+	if _, err := New[int64](math.MinInt64, math.MaxInt64-1, nil); err != nil {
+		t.Errorf("Expected almost all of int64 to be allowed but got %v", err)
+	}
+}
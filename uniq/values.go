@@ -0,0 +1,70 @@
+package uniq
+
+// A ValueSeq hands out the values of a caller-provided set in random order,
+// each one only once. It is not safe for concurrent use.
+type ValueSeq[T comparable] struct {
+	values  []T
+	indexes map[T]int
+	seq     *RandomSeq[int] // over indexes into values
+}
+
+// FromValues returns a ValueSeq over exactly the given values. Duplicates
+// are dropped, so each distinct value is returned once.
+func FromValues[T comparable](values []T) *ValueSeq[T] {
+	return FromValuesWithSource(nil, values)
+}
+
+// FromValuesWithSource is like FromValues, but draws from src instead of the
+// package-global source.
+func FromValuesWithSource[T comparable](src Source, values []T) *ValueSeq[T] {
+	vs := &ValueSeq[T]{indexes: make(map[T]int, len(values))}
+	for _, v := range values {
+		if _, ok := vs.indexes[v]; !ok {
+			vs.indexes[v] = len(vs.values)
+			vs.values = append(vs.values, v)
+		}
+	}
+
+	// An empty set is a range of one index that's already excluded
+	if len(vs.values) == 0 {
+		vs.seq, _ = NewFromSource(src, 0, 0, []int{0})
+	} else {
+		vs.seq, _ = NewFromSource[int](src, 0, len(vs.values)-1, nil)
+	}
+	return vs
+}
+
+// HasMore reports whether the sequence has any more values to return.
+func (v *ValueSeq[T]) HasMore() bool {
+	return v.seq.HasMore()
+}
+
+// Remaining returns how many values are left in the sequence.
+func (v *ValueSeq[T]) Remaining() int {
+	return int(v.seq.Remaining())
+}
+
+// NextValue returns a random value from the set that hasn't been returned
+// before, or ErrExhausted once there are none left.
+func (v *ValueSeq[T]) NextValue() (T, error) {
+	i, err := v.seq.NextValue()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v.values[i], nil
+}
+
+// Consume marks value as consumed, reporting whether it was newly consumed.
+// Values that aren't in the set report false.
+func (v *ValueSeq[T]) Consume(value T) bool {
+	i, ok := v.indexes[value]
+	return ok && v.seq.Consume(i)
+}
+
+// Release returns a consumed value to the sequence, reporting whether it was
+// released. Values that aren't in the set report false.
+func (v *ValueSeq[T]) Release(value T) bool {
+	i, ok := v.indexes[value]
+	return ok && v.seq.Release(i)
+}
@@ -0,0 +1,48 @@
+package uniq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFromValuesDeduplicates(t *testing.T) {
+	seq := FromValues([]string{"alpha", "beta", "alpha", "gamma"})
+	if seq.Remaining() != 3 {
+		t.Fatalf("Expected 3 distinct values but got %d", seq.Remaining())
+	}
+
+	seen := map[string]int{}
+	for seq.HasMore() {
+		value, _ := seq.NextValue()
+		seen[value]++
+	}
+	for _, want := range []string{"alpha", "beta", "gamma"} {
+		if seen[want] != 1 {
+			t.Errorf("Expected one occurrence of %q but got %d", want, seen[want])
+		}
+	}
+}
+
+func TestFromValuesConsumeAndRelease(t *testing.T) {
+	seq := FromValues([]int{7, 11, 13})
+
+	if !seq.Consume(11) || seq.Consume(11) || seq.Consume(99) {
+		t.Errorf("Expected only the first Consume of a member to succeed")
+	}
+	if !seq.Release(11) || seq.Release(99) {
+		t.Errorf("Expected Release of a consumed member to succeed")
+	}
+	if seq.Remaining() != 3 {
+		t.Errorf("Expected 3 values remaining but got %d", seq.Remaining())
+	}
+}
+
+func TestFromValuesEmpty(t *testing.T) {
+	seq := FromValues[int](nil)
+	if seq.HasMore() {
+		t.Errorf("Expected an empty set to have no values")
+	}
+	if _, err := seq.NextValue(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Expected ErrExhausted but got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package byteseq
+
+import "fmt"
+
+// Validate checks that the sequence's internal bookkeeping is consistent:
+// that the count of remaining values agrees with the consumed bitmap, that
+// the exclusions used by Reset are all consumed, and that the pool of
+// values waiting to be drawn matches the bitmap. A problem is reported as
+// an error wrapping ErrInvalidState. A sequence that is only ever used
+// through its methods always validates; Validate is a safety net for state
+// that has come from elsewhere. Repair fixes what it finds.
+func (r *RandomByteSeq) Validate() error {
+	r.lock()
+	defer r.unlock()
+	return r.validate()
+}
+
+// Repair brings the sequence's bookkeeping back into line with its consumed
+// bitmap, which is taken to be the truth, and reports whether anything needed
+// fixing. Excluded values that aren't marked as consumed become consumed,
+// reservations on values that aren't consumed are dropped, and the
+// remaining values are recounted. If the pool had to be rebuilt, a
+// seeded sequence no longer draws the values it would have drawn before.
+func (r *RandomByteSeq) Repair() bool {
+	r.lock()
+	defer r.unlock()
+
+	if r.validate() == nil {
+		return false
+	}
+
+	r.consumedBytes = r.consumedBytes.Union(r.excludedBytes)
+	r.remainingValues = 256 - r.consumedBytes.Count()
+	r.excludedRemaining = 256 - r.excludedBytes.Count()
+	if r.hasPeeked && r.valueHasBeenConsumed(r.peeked) {
+		r.hasPeeked = false
+	}
+	r.reserved = r.reserved.Intersect(r.consumedBytes)
+	r.rebuildPool()
+	r.checkWatermarks()
+	return true
+}
+
+func (r *RandomByteSeq) validate() error {
+	if r.excludedBytes.Difference(r.consumedBytes) != (ByteSet{}) {
+		return fmt.Errorf("%w: excluded values are not consumed", ErrInvalidState)
+	}
+
+	unconsumed := 256 - r.consumedBytes.Count()
+	if r.remainingValues != unconsumed {
+		return fmt.Errorf("%w: %d values remaining, but %d are unconsumed", ErrInvalidState, r.remainingValues, unconsumed)
+	}
+	if excluded := r.excludedBytes.Count(); r.excludedRemaining != 256-excluded {
+		return fmt.Errorf("%w: %d values available after a reset, but %d are excluded", ErrInvalidState, r.excludedRemaining, excluded)
+	}
+
+	for i, b := range r.pool {
+		if int(r.position[b]) != i {
+			return fmt.Errorf("%w: pool position of 0x%02x is wrong", ErrInvalidState, b)
+		}
+		if (i < r.remainingValues) == r.valueHasBeenConsumed(b) {
+			return fmt.Errorf("%w: pool entry 0x%02x disagrees with the bitmap", ErrInvalidState, b)
+		}
+	}
+
+	if r.hasPeeked && r.valueHasBeenConsumed(r.peeked) {
+		return fmt.Errorf("%w: peeked value 0x%02x is consumed", ErrInvalidState, r.peeked)
+	}
+	if r.reserved.Difference(r.consumedBytes) != (ByteSet{}) {
+		return fmt.Errorf("%w: reserved values are not consumed", ErrInvalidState)
+	}
+	return nil
+}
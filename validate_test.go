@@ -0,0 +1,53 @@
+package byteseq
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateAcceptsNormalUse(t *testing.T) {
+	byteSeq := NewRandomSeq([]byte{0x01, 0x02})
+	_, _ = byteSeq.NextN(100)
+	_, _ = byteSeq.Consume(0x03)
+	byteSeq.Release(0x03)
+	_, _ = byteSeq.Peek()
+
+	if err := byteSeq.Validate(); err != nil {
+		t.Errorf("Expected a sequence used through its methods to validate, got %v", err)
+	}
+	if byteSeq.Repair() {
+		t.Errorf("Expected Repair to find nothing to fix")
+	}
+}
+
+func TestRepairFixesDrift(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(r *RandomByteSeq)
+	}{
+		{"wrong count", func(r *RandomByteSeq) { r.remainingValues = 17 }},
+		{"bitmap changed behind the pool", func(r *RandomByteSeq) { r.consumedBytes.Add(r.pool[0]) }},
+		{"exclusion not consumed", func(r *RandomByteSeq) { r.excludedBytes.Add(r.pool[0]) }},
+		{"pool out of order", func(r *RandomByteSeq) { r.pool[0], r.pool[255] = r.pool[255], r.pool[0] }},
+	}
+
+	for _, test := range tests {
+		byteSeq := NewRandomSeq([]byte{0x80})
+		_, _ = byteSeq.NextN(10)
+		test.corrupt(byteSeq)
+
+		if err := byteSeq.Validate(); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("%s: expected ErrInvalidState, got %v", test.name, err)
+		}
+		if !byteSeq.Repair() {
+			t.Errorf("%s: expected Repair to report a fix", test.name)
+		}
+		if err := byteSeq.Validate(); err != nil {
+			t.Errorf("%s: expected the repaired sequence to validate, got %v", test.name, err)
+		}
+		if got, want := byteSeq.Remaining(), 256-len(byteSeq.Consumed()); got != want {
+			t.Errorf("%s: expected %d remaining after repair, got %d", test.name, want, got)
+		}
+		checkPool(t, byteSeq)
+	}
+}
@@ -0,0 +1,66 @@
+package byteseq
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// WithWeights makes draws weighted rather than uniform. Each draw picks
+// among the values that are left with probability proportional to their
+// weight, so values with large weights tend to come out early and those
+// with small weights late, while each value is still returned only once.
+// Values with a weight of zero are only drawn once every value with a
+// positive weight has gone, and then uniformly. Weights must be finite and
+// not negative.
+func WithWeights(weights [256]float64) Option {
+	return func(c *config) {
+		for b, w := range weights {
+			if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+				c.fail("weight %v for 0x%02x is not a finite, non-negative number", w, b)
+				return
+			}
+		}
+		c.weights = &weights
+	}
+}
+
+// weightedUnconsumed returns a value that hasn't been consumed yet, chosen
+// with probability proportional to its weight. The sequence must not be
+// exhausted.
+func (r *RandomByteSeq) weightedUnconsumed() byte {
+	remaining := r.pool[:r.remainingValues]
+
+	total := 0.0
+	for _, b := range remaining {
+		total += r.weights[b]
+	}
+	if total == 0 {
+		return remaining[r.intN(len(remaining))]
+	}
+
+	target := r.float64() * total
+	for _, b := range remaining {
+		target -= r.weights[b]
+		if target < 0 {
+			return b
+		}
+	}
+
+	// Rounding can leave target a hair above zero after the last value, so
+	// fall back to the last value with any weight
+	for i := len(remaining) - 1; i >= 0; i-- {
+		if r.weights[remaining[i]] > 0 {
+			return remaining[i]
+		}
+	}
+	return remaining[len(remaining)-1]
+}
+
+// float64 returns a uniformly distributed number in [0, 1) from the
+// sequence's own source, falling back to the package-global one.
+func (r *RandomByteSeq) float64() float64 {
+	if r.src == nil {
+		return rand.Float64()
+	}
+	return float64(r.src.Uint64()>>11) / (1 << 53)
+}
@@ -0,0 +1,52 @@
+package byteseq
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWeightedDrawsFavourHeavyValues(t *testing.T) {
+	var weights [256]float64
+	for i := range weights {
+		weights[i] = 1
+	}
+	weights[0x10] = 1000
+	weights[0x20] = 0
+
+	heavyFirst, zeroLast := 0, 0
+	for round := 0; round < 100; round++ {
+		byteSeq := NewRandomSeq(nil, WithWeights(weights), WithSeed(int64(round)))
+		values := byteSeq.Drain()
+		if len(values) != 256 {
+			t.Fatalf("Expected all 256 values but got %d", len(values))
+		}
+		if values[0] == 0x10 {
+			heavyFirst++
+		}
+		if values[255] == 0x20 {
+			zeroLast++
+		}
+	}
+
+	// With this weight the heavy value comes first about 80% of the time
+	if heavyFirst < 60 {
+		t.Errorf("Expected the heavy value to usually come first, but it did %d times in 100", heavyFirst)
+	}
+	if zeroLast != 100 {
+		t.Errorf("Expected the zero-weight value to always come last, but it did %d times in 100", zeroLast)
+	}
+}
+
+func TestWeightsMustBeValid(t *testing.T) {
+	var weights [256]float64
+	weights[3] = math.NaN()
+	if _, err := NewRandomSeqChecked(nil, WithWeights(weights)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a NaN weight but got %v", err)
+	}
+
+	weights[3] = -1
+	if _, err := NewRandomSeqChecked(nil, WithWeights(weights)); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption for a negative weight but got %v", err)
+	}
+}